@@ -0,0 +1,79 @@
+package tarantool
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff is a policy of pauses between reconnect attempts.
+// It is specified in Opts.Backoff.
+type Backoff interface {
+	// NextDelay returns pause after failed reconnect attempt. Attempts are
+	// numbered from zero, prev is a pause returned for previous attempt.
+	NextDelay(attempt uint, prev time.Duration) time.Duration
+}
+
+// ConstantBackoff pauses for Delay between all attempts. It is used if
+// Opts.Backoff is not specified.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+// NextDelay returns Delay.
+func (b ConstantBackoff) NextDelay(attempt uint, prev time.Duration) time.Duration {
+	return b.Delay
+}
+
+// ExponentialBackoff pauses for Base after first attempt and multiplies
+// pause by Multiplier (2 by default) after each next one. Pause does not
+// exceed Cap, if it is specified.
+type ExponentialBackoff struct {
+	Base       time.Duration
+	Cap        time.Duration
+	Multiplier float64
+}
+
+// NextDelay returns Base * Multiplier^attempt limited by Cap.
+func (b ExponentialBackoff) NextDelay(attempt uint, prev time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(b.Base) * math.Pow(multiplier, float64(attempt))
+	if b.Cap > 0 && delay > float64(b.Cap) {
+		return b.Cap
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// DecorrelatedJitterBackoff pauses for random time between Base and three
+// times previous pause, limited by Cap. Random pauses spread reconnects of
+// many clients after server restart.
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Cap  time.Duration
+}
+
+// NextDelay returns random pause in range [Base, 3 * prev] limited by Cap.
+func (b DecorrelatedJitterBackoff) NextDelay(attempt uint, prev time.Duration) time.Duration {
+	if prev < b.Base {
+		prev = b.Base
+	}
+	upper := prev * 3
+	if upper < prev {
+		// overflow
+		upper = time.Duration(math.MaxInt64)
+	}
+	delay := b.Base
+	if upper > b.Base {
+		delay += time.Duration(rand.Int63n(int64(upper - b.Base)))
+	}
+	if b.Cap > 0 && delay > b.Cap {
+		delay = b.Cap
+	}
+	return delay
+}
@@ -0,0 +1,113 @@
+package tarantool_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	b := ConstantBackoff{Delay: time.Second}
+	for attempt := uint(0); attempt < 5; attempt++ {
+		if d := b.NextDelay(attempt, time.Second); d != time.Second {
+			t.Errorf("Unexpected delay of attempt %d: %s", attempt, d)
+		}
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Base: 100 * time.Millisecond, Cap: time.Second}
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	var delay time.Duration
+	for attempt, exp := range expected {
+		delay = b.NextDelay(uint(attempt), delay)
+		if delay != exp {
+			t.Errorf("Unexpected delay of attempt %d: %s, expected %s", attempt, delay, exp)
+		}
+	}
+	if d := b.NextDelay(1000, delay); d != time.Second {
+		t.Errorf("Delay is not capped on overflow: %s", d)
+	}
+
+	b = ExponentialBackoff{Base: 100 * time.Millisecond, Multiplier: 3}
+	if d := b.NextDelay(2, 0); d != 900*time.Millisecond {
+		t.Errorf("Unexpected delay with multiplier: %s", d)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: 100 * time.Millisecond, Cap: 2 * time.Second}
+	var delay time.Duration
+	distinct := make(map[time.Duration]bool)
+	for attempt := uint(0); attempt < 100; attempt++ {
+		prev := delay
+		if prev < b.Base {
+			prev = b.Base
+		}
+		delay = b.NextDelay(attempt, delay)
+		if delay < b.Base || delay > b.Cap || delay > 3*prev {
+			t.Fatalf("Delay %s of attempt %d is out of range", delay, attempt)
+		}
+		distinct[delay] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("Delays are not random: %v", distinct)
+	}
+}
+
+func TestBackoffReconnectEvents(t *testing.T) {
+	// address with no listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err.Error())
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	events := make(chan ConnEvent, 16)
+	conn, err := Connect(addr, Opts{
+		SkipSchema:    true,
+		Backoff:       ExponentialBackoff{Base: 10 * time.Millisecond},
+		MaxReconnects: 3,
+		Notify:        events,
+		Logger:        &recordingLogger{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect with reconnects: %s", err.Error())
+	}
+	defer conn.Close()
+
+	expected := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		80 * time.Millisecond,
+	}
+	for attempt, delay := range expected {
+		select {
+		case event := <-events:
+			if event.Kind != ReconnectFailed || event.Attempt != uint(attempt) || event.Delay != delay {
+				t.Errorf("Unexpected event: %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("ReconnectFailed event %d is not received", attempt)
+		}
+	}
+	select {
+	case event := <-events:
+		if event.Kind != Closed {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Closed event is not received")
+	}
+}
@@ -1,6 +1,9 @@
 package tarantool
 
 import (
+	"fmt"
+	"math"
+
 	"gopkg.in/vmihailenco/msgpack.v2"
 )
 
@@ -84,3 +87,599 @@ func (o OpSplice) EncodeMsgpack(enc *msgpack.Encoder) error {
 	enc.EncodeString(o.Replace)
 	return nil
 }
+
+// Operations is a builder of update operations for Update* and Upsert*.
+// Each method appends one operation and returns the builder, so calls could
+// be chained:
+//
+//	ops := tarantool.NewOperations().Add(1, 5).Assign("name", "Bob")
+//	conn.Update("users", "primary", tarantool.UintKey{10}, ops)
+//
+// Field could be specified as a field number (zero based, negative numbers
+// count from the end of tuple), as a field name or as a JSON path
+// (field names and JSON paths require tarantool 2.3+).
+//
+// Arguments are checked when operation is added, first error is returned
+// on encoding (ie when request is sent). If connection has loaded Schema,
+// operations are also validated against space format before request is sent.
+type Operations struct {
+	ops []operation
+	err error
+}
+
+type operation struct {
+	op    string
+	field interface{}
+	arg   interface{}
+	// splice arguments
+	pos, len int
+}
+
+// NewOperations returns empty update operations builder.
+func NewOperations() *Operations {
+	return &Operations{}
+}
+
+// Add appends '+' operation: field is incremented by arg.
+func (ops *Operations) Add(field interface{}, arg interface{}) *Operations {
+	return ops.appendNumeric("+", field, arg)
+}
+
+// Subtract appends '-' operation: field is decremented by arg.
+func (ops *Operations) Subtract(field interface{}, arg interface{}) *Operations {
+	return ops.appendNumeric("-", field, arg)
+}
+
+// BitwiseAnd appends '&' operation: bitwise AND of field and arg.
+func (ops *Operations) BitwiseAnd(field interface{}, arg uint64) *Operations {
+	return ops.addOp("&", field, arg)
+}
+
+// BitwiseOr appends '|' operation: bitwise OR of field and arg.
+func (ops *Operations) BitwiseOr(field interface{}, arg uint64) *Operations {
+	return ops.addOp("|", field, arg)
+}
+
+// BitwiseXor appends '^' operation: bitwise XOR of field and arg.
+func (ops *Operations) BitwiseXor(field interface{}, arg uint64) *Operations {
+	return ops.addOp("^", field, arg)
+}
+
+// Splice appends ':' operation: len characters of string field starting
+// from pos (one based, negative counts from the end) are replaced with
+// replace string.
+func (ops *Operations) Splice(field interface{}, pos, length int, replace string) *Operations {
+	if ops.err == nil && length < 0 {
+		ops.err = fmt.Errorf("splice length should not be negative: %d", length)
+	}
+	ops.addOp(":", field, replace)
+	if ops.err == nil {
+		last := &ops.ops[len(ops.ops)-1]
+		last.pos, last.len = pos, length
+	}
+	return ops
+}
+
+// Insert appends '!' operation: arg is inserted before field.
+func (ops *Operations) Insert(field interface{}, arg interface{}) *Operations {
+	return ops.addOp("!", field, arg)
+}
+
+// Delete appends '#' operation: count fields are deleted starting from field.
+func (ops *Operations) Delete(field interface{}, count uint) *Operations {
+	if ops.err == nil && count == 0 {
+		ops.err = fmt.Errorf("count of deleted fields should be positive")
+	}
+	return ops.addOp("#", field, count)
+}
+
+// Assign appends '=' operation: field is set to arg.
+func (ops *Operations) Assign(field interface{}, arg interface{}) *Operations {
+	return ops.addOp("=", field, arg)
+}
+
+// Err returns first error happened while building operations.
+func (ops *Operations) Err() error {
+	return ops.err
+}
+
+// Len returns number of operations.
+func (ops *Operations) Len() int {
+	return len(ops.ops)
+}
+
+func (ops *Operations) appendNumeric(op string, field interface{}, arg interface{}) *Operations {
+	if ops.err == nil && !isNumber(arg) {
+		ops.err = fmt.Errorf("operation '%s' expects numeric argument, got %T", op, arg)
+	}
+	return ops.addOp(op, field, arg)
+}
+
+func (ops *Operations) addOp(op string, field interface{}, arg interface{}) *Operations {
+	if ops.err != nil {
+		return ops
+	}
+	switch f := field.(type) {
+	case int:
+	case int8:
+		field = int(f)
+	case int16:
+		field = int(f)
+	case int32:
+		field = int(f)
+	case int64:
+		if f > int64(maxFieldNo) || f < -int64(maxFieldNo)-1 {
+			ops.err = fmt.Errorf("operation '%s': field number %d overflows int", op, f)
+			return ops
+		}
+		field = int(f)
+	case uint:
+		if uint64(f) > maxFieldNo {
+			ops.err = fmt.Errorf("operation '%s': field number %d overflows int", op, f)
+			return ops
+		}
+		field = int(f)
+	case uint8:
+		field = int(f)
+	case uint16:
+		field = int(f)
+	case uint32:
+		field = int(f)
+	case uint64:
+		if f > maxFieldNo {
+			ops.err = fmt.Errorf("operation '%s': field number %d overflows int", op, f)
+			return ops
+		}
+		field = int(f)
+	case string:
+		if f == "" {
+			ops.err = fmt.Errorf("operation '%s': empty field name", op)
+			return ops
+		}
+	default:
+		ops.err = fmt.Errorf("operation '%s': unsupported field type %T", op, field)
+		return ops
+	}
+	ops.ops = append(ops.ops, operation{op: op, field: field, arg: arg})
+	return ops
+}
+
+// maxFieldNo is a maximum field number, which fits int.
+const maxFieldNo = uint64(^uint(0) >> 1)
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	}
+	return false
+}
+
+// Validate checks operations against space format.
+// Fields referenced by name should exist in space format, and type of field
+// should be compatible with arithmetic, bitwise and splice operations.
+// For JSON paths only existence of top-level field is checked, since type of
+// nested value is unknown.
+func (ops *Operations) Validate(space *Space) error {
+	if ops.err != nil {
+		return ops.err
+	}
+	if space == nil {
+		return nil
+	}
+	for _, o := range ops.ops {
+		field, isPath, err := o.lookupField(space)
+		if err != nil {
+			return err
+		}
+		if field == nil || isPath || field.Type == "" {
+			continue
+		}
+		var ok bool
+		switch o.op {
+		case "+", "-":
+			ok = isNumericFieldType(field.Type)
+		case "&", "|", "^":
+			ok = isUnsignedFieldType(field.Type)
+		case ":":
+			ok = isStringFieldType(field.Type)
+		default:
+			ok = true
+		}
+		if !ok {
+			return fmt.Errorf("operation '%s' is not applicable to field '%s' of type '%s' in space %s",
+				o.op, fieldDisplayName(field), field.Type, space.Name)
+		}
+	}
+	return nil
+}
+
+// lookupField returns field of space format which is a target of operation.
+// It returns nil field if target could not be determined statically, and
+// isPath if operation targets value nested in the field.
+func (o *operation) lookupField(space *Space) (field *Field, isPath bool, err error) {
+	switch f := o.field.(type) {
+	case int:
+		if f < 0 || uint64(f) > math.MaxUint32 {
+			return nil, false, nil
+		}
+		return space.FieldsById[uint32(f)], false, nil
+	case string:
+		name, isPath := jsonPathHead(f)
+		if name == "" {
+			return nil, isPath, nil
+		}
+		if field, ok := space.Fields[name]; ok {
+			return field, isPath, nil
+		}
+		if len(space.Fields) == 0 {
+			return nil, isPath, nil
+		}
+		if isPath {
+			return nil, isPath, fmt.Errorf("operation '%s': space %s has no field '%s' (path '%s')", o.op, space.Name, name, f)
+		}
+		return nil, isPath, fmt.Errorf("operation '%s': space %s has no field '%s'", o.op, space.Name, name)
+	}
+	return nil, false, nil
+}
+
+// jsonPathHead returns name of top-level field referenced by JSON path.
+// For paths starting with index (ie "[1].a") it returns empty name.
+func jsonPathHead(path string) (name string, isPath bool) {
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' || path[i] == '[' {
+			return path[:i], true
+		}
+	}
+	return path, false
+}
+
+func fieldDisplayName(f *Field) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return fmt.Sprintf("#%d", f.Id)
+}
+
+func isNumericFieldType(t string) bool {
+	switch t {
+	case "unsigned", "uint", "integer", "int", "number", "num",
+		"double", "decimal", "scalar", "any", "*":
+		return true
+	}
+	return false
+}
+
+func isUnsignedFieldType(t string) bool {
+	switch t {
+	case "unsigned", "uint", "scalar", "any", "*":
+		return true
+	}
+	return false
+}
+
+func isStringFieldType(t string) bool {
+	switch t {
+	case "string", "str", "scalar", "any", "*":
+		return true
+	}
+	return false
+}
+
+// EncodeMsgpack encodes operations as list of update operations. It returns
+// error of first invalid operation added.
+func (ops *Operations) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if ops.err != nil {
+		return ops.err
+	}
+	enc.EncodeSliceLen(len(ops.ops))
+	for i := range ops.ops {
+		o := &ops.ops[i]
+		if o.op == ":" {
+			enc.EncodeSliceLen(5)
+		} else {
+			enc.EncodeSliceLen(3)
+		}
+		enc.EncodeString(o.op)
+		switch f := o.field.(type) {
+		case int:
+			enc.EncodeInt(f)
+		case string:
+			enc.EncodeString(f)
+		}
+		if o.op == ":" {
+			enc.EncodeInt(o.pos)
+			enc.EncodeInt(o.len)
+		}
+		if err := enc.Encode(o.arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Key is utility type for passing composite keys of any number of parts
+// to Select*, Update* and Delete*.
+// Parts are encoded immediately when they are added, so no intermediate
+// []interface{} is allocated:
+//
+//	key := tarantool.NewKey().Str("alice").Uint(42).UUID(id)
+//	conn.Select("users", "name_age_id", 0, 1, tarantool.IterEq, key)
+//
+// Key created with NewIndexKey or Schema.IndexKey checks every part against
+// type of corresponding index part.
+// First error is returned by Err and on encoding.
+type Key struct {
+	buf   smallWBuf
+	enc   *msgpack.Encoder
+	n     int
+	index *Index
+	err   error
+}
+
+// NewKey returns empty composite key.
+func NewKey() *Key {
+	k := &Key{}
+	k.enc = msgpack.NewEncoder(&k.buf)
+	return k
+}
+
+// NewIndexKey returns empty composite key for index.
+// Parts added to the key are checked against index parts types.
+func NewIndexKey(index *Index) *Key {
+	k := NewKey()
+	k.index = index
+	return k
+}
+
+// IndexKey returns empty composite key for index of space.
+// Space and index could be specified by name or by number.
+func (schema *Schema) IndexKey(space, index interface{}) (*Key, error) {
+	if schema == nil {
+		return nil, SchemaError{"Schema is not loaded"}
+	}
+	spaceNo, indexNo, err := schema.resolveSpaceIndex(space, index)
+	if err != nil {
+		return nil, err
+	}
+	sp, ok := schema.SpacesById[spaceNo]
+	if !ok {
+		return nil, SchemaError{fmt.Sprintf("there is no space with id %d", spaceNo)}
+	}
+	idx, ok := sp.IndexesById[indexNo]
+	if !ok {
+		return nil, SchemaError{fmt.Sprintf("space %s has not index with id %d", sp.Name, indexNo)}
+	}
+	return NewIndexKey(idx), nil
+}
+
+// Uint appends unsigned integer part.
+func (k *Key) Uint(v uint64) *Key {
+	if k.check("unsigned", isUintPartType) {
+		k.enc.EncodeUint64(v)
+	}
+	return k
+}
+
+// Int appends signed integer part.
+func (k *Key) Int(v int64) *Key {
+	accepts := isIntPartType
+	if v >= 0 {
+		accepts = isUintPartType
+	}
+	if k.check("integer", accepts) {
+		k.enc.EncodeInt64(v)
+	}
+	return k
+}
+
+// Float appends floating point part.
+func (k *Key) Float(v float64) *Key {
+	if k.check("double", isFloatPartType) {
+		k.enc.EncodeFloat64(v)
+	}
+	return k
+}
+
+// Str appends string part.
+func (k *Key) Str(v string) *Key {
+	if k.check("string", isStringPartType) {
+		k.enc.EncodeString(v)
+	}
+	return k
+}
+
+// Bool appends boolean part.
+func (k *Key) Bool(v bool) *Key {
+	if k.check("boolean", isBoolPartType) {
+		k.enc.EncodeBool(v)
+	}
+	return k
+}
+
+// Bytes appends binary part.
+func (k *Key) Bytes(v []byte) *Key {
+	if k.check("varbinary", isBinaryPartType) {
+		k.enc.EncodeBytes(v)
+	}
+	return k
+}
+
+// UUID appends UUID part (tarantool 2.4.1+).
+// github.com/google/uuid.UUID could be passed directly.
+func (k *Key) UUID(v [16]byte) *Key {
+	if k.check("uuid", isUUIDPartType) {
+		k.buf.Write([]byte{0xd8, uuidExtId})
+		k.buf.Write(v[:])
+	}
+	return k
+}
+
+// Nil appends nil part. Index part should be nullable.
+func (k *Key) Nil() *Key {
+	if k.err == nil && k.index != nil && k.n < len(k.index.Fields) && !k.index.Fields[k.n].IsNullable {
+		k.err = fmt.Errorf("key part %d of index %s is not nullable", k.n+1, k.index.Name)
+	}
+	if k.check("nil", nil) {
+		k.enc.EncodeNil()
+	}
+	return k
+}
+
+// Value appends part of any type. Go integers, floats, strings, booleans
+// and byte slices are encoded and checked as with typed methods, other
+// values are encoded with msgpack and are not checked.
+func (k *Key) Value(v interface{}) *Key {
+	switch v := v.(type) {
+	case nil:
+		return k.Nil()
+	case int:
+		return k.Int(int64(v))
+	case int8:
+		return k.Int(int64(v))
+	case int16:
+		return k.Int(int64(v))
+	case int32:
+		return k.Int(int64(v))
+	case int64:
+		return k.Int(v)
+	case uint:
+		return k.Uint(uint64(v))
+	case uint8:
+		return k.Uint(uint64(v))
+	case uint16:
+		return k.Uint(uint64(v))
+	case uint32:
+		return k.Uint(uint64(v))
+	case uint64:
+		return k.Uint(v)
+	case float32:
+		return k.Float(float64(v))
+	case float64:
+		return k.Float(v)
+	case string:
+		return k.Str(v)
+	case bool:
+		return k.Bool(v)
+	case []byte:
+		return k.Bytes(v)
+	}
+	if k.check("", nil) {
+		if err := k.enc.Encode(v); err != nil {
+			k.err = err
+		}
+	}
+	return k
+}
+
+// Len returns number of key parts.
+func (k *Key) Len() int {
+	return k.n
+}
+
+// Err returns first error happened while building key.
+func (k *Key) Err() error {
+	return k.err
+}
+
+// Reset removes all parts from key, so it could be reused.
+// Index of the key is kept.
+func (k *Key) Reset() {
+	k.buf.b = k.buf.b[:0]
+	k.n = 0
+	k.err = nil
+}
+
+func (k *Key) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if k.err != nil {
+		return k.err
+	}
+	if err := enc.EncodeSliceLen(k.n); err != nil {
+		return err
+	}
+	_, err := enc.Writer().Write(k.buf.b)
+	return err
+}
+
+// check validates that next part of kind is compatible with index part.
+// accepts reports if type of index part accepts the kind, nil accepts all.
+func (k *Key) check(kind string, accepts func(string) bool) bool {
+	if k.err != nil {
+		return false
+	}
+	if k.index != nil {
+		if k.n >= len(k.index.Fields) {
+			k.err = fmt.Errorf("index %s has %d parts, key part %d is excess",
+				k.index.Name, len(k.index.Fields), k.n+1)
+			return false
+		}
+		part := k.index.Fields[k.n]
+		if accepts != nil && part.Type != "" && !accepts(part.Type) {
+			k.err = fmt.Errorf("key part %d of index %s has type %s, %s is given",
+				k.n+1, k.index.Name, part.Type, kind)
+			return false
+		}
+	}
+	k.n++
+	return true
+}
+
+const uuidExtId = 2
+
+func isUintPartType(t string) bool {
+	switch t {
+	case "unsigned", "uint", "integer", "int", "number", "num", "scalar":
+		return true
+	}
+	return false
+}
+
+func isIntPartType(t string) bool {
+	switch t {
+	case "integer", "int", "number", "num", "scalar":
+		return true
+	}
+	return false
+}
+
+func isFloatPartType(t string) bool {
+	switch t {
+	case "double", "number", "num", "scalar":
+		return true
+	}
+	return false
+}
+
+func isStringPartType(t string) bool {
+	switch t {
+	case "string", "str", "scalar":
+		return true
+	}
+	return false
+}
+
+func isBoolPartType(t string) bool {
+	switch t {
+	case "boolean", "scalar":
+		return true
+	}
+	return false
+}
+
+func isBinaryPartType(t string) bool {
+	switch t {
+	case "varbinary", "scalar":
+		return true
+	}
+	return false
+}
+
+func isUUIDPartType(t string) bool {
+	switch t {
+	case "uuid", "scalar":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package tarantool_test
+
+import (
+	"testing"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestOperationsValidate(t *testing.T) {
+	count := &Field{Id: 0, Name: "count", Type: "unsigned"}
+	attrs := &Field{Id: 1, Name: "attrs", Type: "map"}
+	space := &Space{
+		Name:       "test",
+		Fields:     map[string]*Field{"count": count, "attrs": attrs},
+		FieldsById: map[uint32]*Field{0: count, 1: attrs},
+	}
+
+	valid := []*Operations{
+		NewOperations().Add("count", 1),
+		NewOperations().Add("attrs.count", 1),
+		NewOperations().BitwiseOr("attrs[1]", 1),
+		NewOperations().Splice("attrs.name", 0, 1, "a"),
+	}
+	for i, ops := range valid {
+		if err := ops.Validate(space); err != nil {
+			t.Errorf("Valid operations %d are rejected: %s", i, err)
+		}
+	}
+
+	invalid := []*Operations{
+		NewOperations().Add("attrs", 1),
+		NewOperations().Add(1, 1),
+		NewOperations().Add("unknown.count", 1),
+	}
+	for i, ops := range invalid {
+		if err := ops.Validate(space); err == nil {
+			t.Errorf("Invalid operations %d are accepted", i)
+		}
+	}
+}
+
+func TestOperationsFieldOverflow(t *testing.T) {
+	if NewOperations().Assign(^uint64(0), 1).Err() == nil {
+		t.Errorf("Overflowing uint64 field number is accepted")
+	}
+	if NewOperations().Assign(^uint(0), 1).Err() == nil {
+		t.Errorf("Overflowing uint field number is accepted")
+	}
+	if err := NewOperations().Assign(uint64(3), 1).Err(); err != nil {
+		t.Errorf("Field number is rejected: %s", err)
+	}
+}
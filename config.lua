@@ -43,6 +43,7 @@ box.once("init", function()
     --box.schema.user.grant('guest', 'read,write,execute', 'universe')
     box.schema.func.create('box.info')
     box.schema.func.create('simple_incr')
+    box.schema.func.create('recreate_space', {setuid = true})
 
     -- auth testing: access control
     box.schema.user.create('test', {password = 'test'})
@@ -51,11 +52,50 @@ box.once("init", function()
     box.schema.user.grant('test', 'read,write', 'space', 'schematest')
 end)
 
+box.once("init_schema_ext", function()
+    box.schema.sequence.create('test_seq', {start = 10, step = 2, if_not_exists = true})
+
+    local s = box.schema.space.create('schematest_ext', {
+        id = 516,
+        if_not_exists = true,
+        format = {
+            {name = "id", type = "unsigned"},
+            {name = "name", type = "string", is_nullable = true, collation = "unicode_ci"},
+        },
+    })
+    s:create_index('primary', {
+        type = 'tree',
+        parts = {{field = 1, type = 'unsigned'}},
+        sequence = 'test_seq',
+        if_not_exists = true,
+    })
+    s:create_index('name', {
+        type = 'tree',
+        unique = false,
+        parts = {{field = 2, type = 'string', collation = 'unicode_ci', is_nullable = true}},
+        if_not_exists = true,
+    })
+
+    box.schema.user.grant('test', 'read,write', 'space', 'schematest_ext')
+    box.schema.user.grant('test', 'read,write', 'sequence', 'test_seq')
+end)
+
 local function simple_incr(a)
     return a + 1
 end
 rawset(_G, 'simple_incr', simple_incr)
 
+local function recreate_space(name, id)
+    if box.space[name] ~= nil then
+        box.space[name]:drop()
+    end
+    local s = box.schema.space.create(name, {id = id, temporary = true})
+    s:create_index('primary')
+    box.schema.user.grant('test', 'read,write', 'space', name)
+    return s.id
+end
+rawset(_G, 'recreate_space', recreate_space)
+
 box.space.test:truncate()
 
 --box.schema.user.revoke('guest', 'read,write,execute', 'universe')
@@ -3,10 +3,12 @@ package tarantool
 import (
 	"bufio"
 	"bytes"
+	"container/heap"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net"
 	"runtime"
 	"sync"
@@ -46,11 +48,47 @@ const (
 	LogUnexpectedResultId
 )
 
+// Event kinds are appended to keep values of existing ones.
+const (
+	// SchemaReloaded signals that schema is loaded or reloaded
+	SchemaReloaded ConnEventKind = Closed + 1 + iota
+	// ShutdownRequested signals that Tarantool is shutting down gracefully
+	// (Tarantool 2.10+), connection will be broken soon
+	ShutdownRequested
+)
+
+// Log kinds are appended to keep values of existing ones.
+const (
+	// LogConnected is logged when connection is established.
+	LogConnected ConnLogKind = LogUnexpectedResultId + 1 + iota
+	// LogAuthenticated is logged when user is authenticated.
+	LogAuthenticated
+	// LogSchemaLoaded is logged when schema is loaded or reloaded.
+	LogSchemaLoaded
+	// LogSchemaLoadFailed is logged when schema loading failed.
+	LogSchemaLoadFailed
+	// LogReconnecting is logged before reconnect attempt.
+	LogReconnecting
+	// LogRateLimited is logged when request is dropped due to rate limit.
+	LogRateLimited
+	// LogDisconnected is logged when connection is broken.
+	LogDisconnected
+	// LogClosed is logged when connection is closed.
+	LogClosed
+	// LogShutdownRequested is logged when Tarantool is shutting down.
+	LogShutdownRequested
+)
+
 // ConnEvent is sent throw Notify channel specified in Opts
 type ConnEvent struct {
 	Conn *Connection
 	Kind ConnEventKind
 	When time.Time
+	// Attempt is a number of failed reconnect attempt (starting from zero)
+	// and Delay is a pause before next one. They are set for
+	// ReconnectFailed events.
+	Attempt uint
+	Delay   time.Duration
 }
 
 var epoch = time.Now()
@@ -114,24 +152,55 @@ func (d defaultLogger) Report(event ConnLogKind, conn *Connection, v ...interfac
 // array of arrays.
 
 type Connection struct {
+	// nextTimeout is epoch time of next check of request timeouts, it is
+	// accessed atomically and placed first to be aligned on 32-bit platforms.
+	nextTimeout int64
+	// rateLimitedLogged is epoch time of last LogRateLimited record,
+	// rateLimited is a number of requests limited since then and
+	// rateLimitedCode is a code of last of them, all are accessed atomically.
+	rateLimitedLogged int64
+	rateLimited       uint64
+	rateLimitedCode   int32
+	// timeoutWake wakes timeouts goroutine to check request, which is
+	// timeouted before nextTimeout.
+	timeoutWake chan struct{}
+
 	addr  string
 	c     net.Conn
 	mutex sync.Mutex
-	// Schema contains schema loaded on connection.
-	Schema    *Schema
-	requestId uint32
+	// logs are records deferred until mutex and shard locks are released,
+	// see deferLog.
+	logs      []logRecord
+	logsMutex sync.Mutex
+	// Schema contains schema loaded on connect, by ReloadSchema or set by
+	// OverrideSchema. It is not updated by automatic reload after
+	// reconnect, use GetSchema to get current schema.
+	Schema *Schema
+	// schema is current schema, it is replaced on reload.
+	schema      *Schema
+	schemaMutex sync.RWMutex
+	requestId   uint32
 	// Greeting contains first message sent by tarantool
 	Greeting *Greeting
 
 	shard      []connShard
 	dirtyShard chan uint32
 
-	control chan struct{}
-	rlimit  chan struct{}
-	opts    Opts
-	state   uint32
-	dec     *msgpack.Decoder
-	lenbuf  [PacketLengthBytes]byte
+	control   chan struct{}
+	rlimit    chan struct{}
+	throttler *throttler
+	opts      Opts
+	state     uint32
+	stats     *connStats
+	// subscribers receive events, see OnEvent.
+	subMutex    sync.Mutex
+	subscribers []*eventQueue
+	eventsDone  bool
+	// remoteAddr is address of last established socket,
+	// it is read without locking conn.mutex.
+	remoteAddr atomic.Value
+	dec        *msgpack.Decoder
+	lenbuf     [PacketLengthBytes]byte
 }
 
 var _ = Connector(&Connection{}) // check compatibility with connector interface
@@ -142,10 +211,13 @@ type connShard struct {
 		first *Future
 		last  **Future
 	}
-	bufmut sync.Mutex
-	buf    smallWBuf
-	enc    *msgpack.Encoder
-	_pad   [16]uint64
+	// deadlines orders requests with timeout by deadline, it is guarded
+	// by rmut.
+	deadlines deadlineHeap
+	bufmut    sync.Mutex
+	buf       smallWBuf
+	enc       *msgpack.Encoder
+	_pad      [16]uint64
 }
 
 // Greeting is a message sent by tarantool on connect.
@@ -158,6 +230,7 @@ type Greeting struct {
 type Opts struct {
 	// Timeout is requests timeout.
 	// Also used to setup net.TCPConn.Set(Read|Write)Deadline
+	// It could be overridden for a request with WithTimeout and WithDeadline.
 	Timeout time.Duration
 	// Reconnect is a pause between reconnection attempts.
 	// If specified, then when tarantool is not reachable or disconnected,
@@ -165,6 +238,32 @@ type Opts struct {
 	// By default, no reconnection attempts are performed,
 	// so once disconnected, connection becomes Closed.
 	Reconnect time.Duration
+	// Backoff is a policy of pauses between reconnection attempts, it
+	// enables reconnects as Reconnect does. By default pauses are constant
+	// and equal to Reconnect.
+	Backoff Backoff
+	// DialTimeout is a timeout of connect to tarantool. By default it is
+	// Reconnect/2, but no more than 5s, or 500ms if Reconnect is zero.
+	DialTimeout time.Duration
+	// KeepAlive is a period of TCP keep-alive probes. Zero means default of
+	// net package, negative value disables keep-alive.
+	KeepAlive time.Duration
+	// ReadBufferSize and WriteBufferSize are sizes of buffers of socket
+	// reader and writer, 128KiB by default.
+	ReadBufferSize  int
+	WriteBufferSize int
+	// ConfigureSocket is called with established tcp or unix connection
+	// before greeting is read, so socket options could be set:
+	//
+	//	opts.ConfigureSocket = func(c net.Conn) error {
+	//		if tcp, ok := c.(*net.TCPConn); ok {
+	//			return tcp.SetNoDelay(false)
+	//		}
+	//		return nil
+	//	}
+	//
+	// If it returns error, connection is closed and dial fails.
+	ConfigureSocket func(c net.Conn) error
 	// MaxReconnects is a maximum reconnect attempts.
 	// After MaxReconnects attempts Connection becomes closed.
 	MaxReconnects uint
@@ -183,8 +282,17 @@ type Opts struct {
 	//                If no request answered during timeout period, this request
 	//                is aborted.
 	//                If no timeout period is set, it will wait forever.
-	// It is required if RateLimit is specified.
+	// It is required if RateLimit, RequestRate or RequestRateByCode
+	// is specified. For request rate limits RLimitWait waits for the
+	// token up to Timeout: if it could not be got in time, request is
+	// aborted immediately.
 	RLimitAction uint
+	// RequestRate limits throughput of all requests with token bucket.
+	// Schema loading and pings are not limited.
+	RequestRate RequestRate
+	// RequestRateByCode limits throughput of requests with specified
+	// request codes (i.e. EvalRequest), in addition to RequestRate.
+	RequestRateByCode map[int32]RequestRate
 	// Concurrency is amount of separate mutexes for request
 	// queues and buffers inside of connection.
 	// It is rounded upto nearest power of 2.
@@ -196,10 +304,23 @@ type Opts struct {
 	// Notify is a channel which receives notifications about Connection status
 	// changes.
 	Notify chan<- ConnEvent
+	// OnEvent receives all events of Connection, including ones happened
+	// during Connect, see Connection.OnEvent.
+	OnEvent func(ConnEvent)
 	// Handle is user specified value, that could be retrivied with Handle() method
 	Handle interface{}
-	// Logger is user specified logger used for error messages
+	// Logger is user specified logger used for error messages.
+	// It is ignored if StructuredLogger is specified.
 	Logger Logger
+	// StructuredLogger is user specified logger receiving all connection
+	// events as structured records. If it is not specified, Logger is used
+	// through AdaptLogger.
+	StructuredLogger StructuredLogger
+	// Interceptors observe requests before send and on completion,
+	// see Interceptor.
+	Interceptors []Interceptor
+	// Retry is a policy of retries of idempotent requests, see RetryPolicy.
+	Retry RetryPolicy
 }
 
 // Connect creates and configures new Connection
@@ -222,10 +343,10 @@ type Opts struct {
 //
 // Note:
 //
-// - If opts.Reconnect is zero (default), then connection either already connected
-// or error is returned.
+// - If opts.Reconnect is zero (default) and opts.Backoff is not set, then
+// connection either already connected or error is returned.
 //
-// - If opts.Reconnect is non-zero, then error will be returned only if authorization// fails. But if Tarantool is not reachable, then it will attempt to reconnect later
+// - If opts.Reconnect is non-zero or opts.Backoff is set, then error will be returned only if authorization// fails. But if Tarantool is not reachable, then it will attempt to reconnect later
 // and will not end attempts on authorization failures.
 func Connect(addr string, opts Opts) (conn *Connection, err error) {
 	conn = &Connection{
@@ -235,6 +356,9 @@ func Connect(addr string, opts Opts) (conn *Connection, err error) {
 		control:   make(chan struct{}),
 		opts:      opts,
 		dec:       msgpack.NewDecoder(&smallBuf{}),
+
+		nextTimeout: math.MaxInt64,
+		timeoutWake: make(chan struct{}, 1),
 	}
 	maxprocs := uint32(runtime.GOMAXPROCS(-1))
 	if conn.opts.Concurrency == 0 || conn.opts.Concurrency > maxprocs*128 {
@@ -248,6 +372,7 @@ func Connect(addr string, opts Opts) (conn *Connection, err error) {
 	}
 	conn.dirtyShard = make(chan uint32, conn.opts.Concurrency*2)
 	conn.shard = make([]connShard, conn.opts.Concurrency)
+	conn.stats = newConnStats()
 	for i := range conn.shard {
 		shard := &conn.shard[i]
 		for j := range shard.requests {
@@ -261,24 +386,45 @@ func Connect(addr string, opts Opts) (conn *Connection, err error) {
 			return nil, errors.New("RLimitAction should be specified to RLimitDone nor RLimitWait")
 		}
 	}
+	if conn.throttler, err = newThrottler(opts); err != nil {
+		return nil, err
+	}
+	if conn.throttler != nil && opts.RLimitAction != RLimitDrop && opts.RLimitAction != RLimitWait {
+		return nil, errors.New("RLimitAction should be specified to RLimitDone nor RLimitWait")
+	}
+
+	if conn.opts.Backoff == nil && conn.opts.Reconnect > 0 {
+		conn.opts.Backoff = ConstantBackoff{conn.opts.Reconnect}
+	}
+
+	if conn.opts.StructuredLogger == nil {
+		if conn.opts.Logger == nil {
+			conn.opts.Logger = defaultLogger{}
+		}
+		conn.opts.StructuredLogger = AdaptLogger(conn.opts.Logger)
+	}
 
-	if conn.opts.Logger == nil {
-		conn.opts.Logger = defaultLogger{}
+	if conn.opts.OnEvent != nil {
+		conn.subscribe(conn.opts.OnEvent)
 	}
 
-	if err = conn.createConnection(false); err != nil {
+	err = conn.createConnection(false)
+	conn.flushLogs()
+	if err != nil {
 		ter, ok := err.(Error)
-		if conn.opts.Reconnect <= 0 {
+		if conn.opts.Backoff == nil {
+			conn.stopEvents()
 			return nil, err
 		} else if ok && (ter.Code == ErrNoSuchUser ||
 			ter.Code == ErrPasswordMismatch) {
 			/* reported auth errors immediatly */
+			conn.stopEvents()
 			return nil, err
 		} else {
 			// without SkipSchema it is useless
 			go func(conn *Connection) {
 				conn.mutex.Lock()
-				defer conn.mutex.Unlock()
+				defer conn.unlock()
 				if err := conn.createConnection(true); err != nil {
 					conn.closeConnection(err, true)
 				}
@@ -288,18 +434,16 @@ func Connect(addr string, opts Opts) (conn *Connection, err error) {
 	}
 
 	go conn.pinger()
-	if conn.opts.Timeout > 0 {
-		go conn.timeouts()
-	}
+	go conn.timeouts()
 
-	// TODO: reload schema after reconnect
 	if !conn.opts.SkipSchema {
 		if err = conn.loadSchema(); err != nil {
 			conn.mutex.Lock()
-			defer conn.mutex.Unlock()
+			defer conn.unlock()
 			conn.closeConnection(err, true)
 			return nil, err
 		}
+		conn.Schema = conn.GetSchema()
 	}
 
 	return conn, err
@@ -320,8 +464,10 @@ func (conn *Connection) ClosedNow() bool {
 func (conn *Connection) Close() error {
 	err := ClientError{ErrConnectionClosed, "connection closed by client"}
 	conn.mutex.Lock()
-	defer conn.mutex.Unlock()
-	return conn.closeConnection(err, true)
+	closeErr := conn.closeConnection(err, true)
+	conn.unlock()
+	conn.flushRateLimited(true)
+	return closeErr
 }
 
 // Addr is configured address of Tarantool socket
@@ -358,11 +504,14 @@ func (conn *Connection) dial() (err error) {
 	var connection net.Conn
 	network := "tcp"
 	address := conn.addr
-	timeout := conn.opts.Reconnect / 2
-	if timeout == 0 {
-		timeout = 500 * time.Millisecond
-	} else if timeout > 5*time.Second {
-		timeout = 5 * time.Second
+	timeout := conn.opts.DialTimeout
+	if timeout <= 0 {
+		timeout = conn.opts.Reconnect / 2
+		if timeout == 0 {
+			timeout = 500 * time.Millisecond
+		} else if timeout > 5*time.Second {
+			timeout = 5 * time.Second
+		}
 	}
 	// Unix socket connection
 	addrLen := len(address)
@@ -382,13 +531,27 @@ func (conn *Connection) dial() (err error) {
 	} else if addrLen >= 4 && address[0:4] == "tcp:" {
 		address = address[4:]
 	}
-	connection, err = net.DialTimeout(network, address, timeout)
+	dialer := net.Dialer{Timeout: timeout, KeepAlive: conn.opts.KeepAlive}
+	connection, err = dialer.Dial(network, address)
 	if err != nil {
 		return
 	}
+	if conn.opts.ConfigureSocket != nil {
+		if err = conn.opts.ConfigureSocket(connection); err != nil {
+			connection.Close()
+			return
+		}
+	}
+	readSize, writeSize := conn.opts.ReadBufferSize, conn.opts.WriteBufferSize
+	if readSize <= 0 {
+		readSize = 128 * 1024
+	}
+	if writeSize <= 0 {
+		writeSize = 128 * 1024
+	}
 	dc := &DeadlineIO{to: conn.opts.Timeout, c: connection}
-	r := bufio.NewReaderSize(dc, 128*1024)
-	w := bufio.NewWriterSize(dc, 128*1024)
+	r := bufio.NewReaderSize(dc, readSize)
+	w := bufio.NewWriterSize(dc, writeSize)
 	greeting := make([]byte, 128)
 	_, err = io.ReadFull(r, greeting)
 	if err != nil {
@@ -414,15 +577,23 @@ func (conn *Connection) dial() (err error) {
 			connection.Close()
 			return err
 		}
+		conn.deferLog(LogLevelDebug, LogAuthenticated, "authenticated",
+			LogField{"user", conn.opts.User})
 	}
 
 	// Only if connected and authenticated
 	conn.lockShards()
 	conn.c = connection
+	conn.remoteAddr.Store(connection.RemoteAddr().String())
 	atomic.StoreUint32(&conn.state, connConnected)
 	conn.unlockShards()
 	go conn.writer(w, connection)
 	go conn.reader(r, connection)
+	if conn.supportsWatchers() {
+		conn.watch(shutdownEventKey)
+	}
+	conn.deferLog(LogLevelInfo, LogConnected, "connected",
+		LogField{"remote_addr", connection.RemoteAddr().String()})
 
 	return
 }
@@ -475,8 +646,14 @@ func (conn *Connection) readAuthResponse(r io.Reader) (err error) {
 
 func (conn *Connection) createConnection(reconnect bool) (err error) {
 	var reconnects uint
+	var delay time.Duration
 	for conn.c == nil && conn.state == connDisconnected {
 		now := time.Now()
+		if reconnect {
+			conn.deferLog(LogLevelDebug, LogReconnecting, "reconnecting",
+				LogField{"attempt", reconnects},
+				LogField{"max_attempts", conn.opts.MaxReconnects})
+		}
 		err = conn.dial()
 		if err == nil || !reconnect {
 			if err == nil {
@@ -485,16 +662,23 @@ func (conn *Connection) createConnection(reconnect bool) (err error) {
 			return
 		}
 		if conn.opts.MaxReconnects > 0 && reconnects > conn.opts.MaxReconnects {
-			conn.opts.Logger.Report(LogLastReconnectFailed, conn, err)
+			conn.deferLog(LogLevelError, LogLastReconnectFailed, "last reconnect failed, giving it up",
+				LogField{"error", err},
+				LogField{"max_attempts", conn.opts.MaxReconnects})
 			err = ClientError{ErrConnectionClosed, "last reconnect failed"}
 			// mark connection as closed to avoid reopening by another goroutine
 			return
 		}
-		conn.opts.Logger.Report(LogReconnectFailed, conn, reconnects, err)
-		conn.notify(ReconnectFailed)
+		delay = conn.opts.Backoff.NextDelay(reconnects, delay)
+		conn.deferLog(LogLevelWarn, LogReconnectFailed, "reconnect failed",
+			LogField{"attempt", reconnects},
+			LogField{"max_attempts", conn.opts.MaxReconnects},
+			LogField{"delay", delay},
+			LogField{"error", err})
+		conn.sendEvent(ConnEvent{Kind: ReconnectFailed, Attempt: reconnects, Delay: delay})
 		reconnects++
-		conn.mutex.Unlock()
-		time.Sleep(now.Add(conn.opts.Reconnect).Sub(time.Now()))
+		conn.unlock()
+		time.Sleep(now.Add(delay).Sub(time.Now()))
 		conn.mutex.Lock()
 	}
 	if conn.state == connClosed {
@@ -504,17 +688,27 @@ func (conn *Connection) createConnection(reconnect bool) (err error) {
 }
 
 func (conn *Connection) closeConnection(neterr error, forever bool) (err error) {
+	var intercepted []*Future
 	conn.lockShards()
-	defer conn.unlockShards()
+	defer func() {
+		conn.unlockShards()
+		for _, fut := range intercepted {
+			conn.complete(fut)
+		}
+	}()
 	if forever {
 		if conn.state != connClosed {
 			close(conn.control)
 			atomic.StoreUint32(&conn.state, connClosed)
 			conn.notify(Closed)
+			conn.deferLog(LogLevelInfo, LogClosed, "connection closed",
+				LogField{"error", neterr})
 		}
 	} else {
 		atomic.StoreUint32(&conn.state, connDisconnected)
 		conn.notify(Disconnected)
+		conn.deferLog(LogLevelWarn, LogDisconnected, "disconnected",
+			LogField{"error", neterr})
 	}
 	if conn.c != nil {
 		err = conn.c.Close()
@@ -522,6 +716,7 @@ func (conn *Connection) closeConnection(neterr error, forever bool) (err error)
 	}
 	for i := range conn.shard {
 		conn.shard[i].buf.Reset()
+		conn.shard[i].deadlines.reset()
 		requests := &conn.shard[i].requests
 		for pos := range requests {
 			fut := requests[pos].first
@@ -529,7 +724,10 @@ func (conn *Connection) closeConnection(neterr error, forever bool) (err error)
 			requests[pos].last = &requests[pos].first
 			for fut != nil {
 				fut.err = neterr
-				fut.markReady(conn)
+				fut.markReadyLocked(conn)
+				if fut.icpt != nil {
+					intercepted = append(intercepted, fut)
+				}
 				fut, fut.next = fut.next, nil
 			}
 		}
@@ -539,12 +737,18 @@ func (conn *Connection) closeConnection(neterr error, forever bool) (err error)
 
 func (conn *Connection) reconnect(neterr error, c net.Conn) {
 	conn.mutex.Lock()
-	defer conn.mutex.Unlock()
-	if conn.opts.Reconnect > 0 {
+	defer conn.unlock()
+	if conn.opts.Backoff != nil {
 		if c == conn.c {
 			conn.closeConnection(neterr, false)
 			if err := conn.createConnection(true); err != nil {
 				conn.closeConnection(err, true)
+			} else {
+				atomic.AddUint64(&conn.stats.reconnects, 1)
+				if !conn.opts.SkipSchema {
+					// schema could be changed while we were disconnected
+					go conn.reloadSchema(conn.c)
+				}
 			}
 		}
 	} else {
@@ -576,17 +780,26 @@ func (conn *Connection) pinger() {
 	for {
 		select {
 		case <-conn.control:
+			conn.flushRateLimited(true)
 			return
 		case <-t.C:
 		}
-		conn.Ping()
+		conn.flushRateLimited(false)
+		conn.pingAsync(&requestScope{noRetry: true, internal: true}).Get()
 	}
 }
 
 func (conn *Connection) notify(kind ConnEventKind) {
+	conn.sendEvent(ConnEvent{Kind: kind})
+}
+
+func (conn *Connection) sendEvent(event ConnEvent) {
+	event.Conn = conn
+	event.When = time.Now()
+	conn.publish(event)
 	if conn.opts.Notify != nil {
 		select {
-		case conn.opts.Notify <- ConnEvent{Kind: kind, Conn: conn, When: time.Now()}:
+		case conn.opts.Notify <- event:
 		default:
 		}
 	}
@@ -628,6 +841,7 @@ func (conn *Connection) writer(w *bufio.Writer, c net.Conn) {
 			conn.reconnect(err, c)
 			return
 		}
+		atomic.AddUint64(&conn.stats.bytesWritten, uint64(packet.Len()))
 		packet.Reset()
 	}
 }
@@ -639,34 +853,59 @@ func (conn *Connection) reader(r *bufio.Reader, c net.Conn) {
 			conn.reconnect(err, c)
 			return
 		}
+		atomic.AddUint64(&conn.stats.bytesRead, uint64(len(conn.lenbuf)+len(respBytes)))
 		resp := &Response{buf: smallBuf{b: respBytes}}
 		err = resp.decodeHeader(conn.dec)
 		if err != nil {
 			conn.reconnect(err, c)
 			return
 		}
-		if fut := conn.fetchFuture(resp.RequestId); fut != nil {
+		if resp.Code == EventRequest {
+			conn.handleEvent(resp)
+		} else if fut := conn.fetchFuture(resp.RequestId); fut != nil {
 			fut.resp = resp
 			fut.markReady(conn)
 		} else {
-			conn.opts.Logger.Report(LogUnexpectedResultId, conn, resp)
+			atomic.AddUint64(&conn.stats.unexpected, 1)
+			conn.log(LogLevelWarn, LogUnexpectedResultId, "unexpected response id",
+				LogField{"request_id", resp.RequestId},
+				LogField{"response", resp})
 		}
 	}
 }
 
-func (conn *Connection) newFuture(requestCode int32) (fut *Future) {
+func (conn *Connection) newFuture(scope *requestScope, requestCode int32) (fut *Future) {
 	fut = &Future{}
+	// request timeout includes time spent waiting for rate limit
+	start := time.Now()
+	deadline := scope.expiration(conn, start)
+	if !deadline.IsZero() && !deadline.After(start) {
+		fut.err = ClientError{ErrTimeouted, "request deadline is exceeded"}
+		conn.stats.requestFailed(fut.err)
+		return
+	}
+	if err := conn.throttle(scope, requestCode, deadline); err != nil {
+		fut.err = err
+		conn.stats.requestFailed(fut.err)
+		conn.logRateLimited(requestCode)
+		return
+	}
 	if conn.rlimit != nil && conn.opts.RLimitAction == RLimitDrop {
 		select {
 		case conn.rlimit <- struct{}{}:
 		default:
 			fut.err = ClientError{ErrRateLimited, "Request is rate limited on client"}
+			conn.stats.requestFailed(fut.err)
+			conn.logRateLimited(requestCode)
 			return
 		}
 	}
 	fut.ready = make(chan struct{})
 	fut.requestId = conn.nextRequestId()
 	fut.requestCode = requestCode
+	if len(conn.opts.Interceptors) > 0 {
+		fut.icpt = conn.newIntercepted(scope, fut)
+	}
 	shardn := fut.requestId & (conn.opts.Concurrency - 1)
 	shard := &conn.shard[shardn]
 	shard.rmut.Lock()
@@ -675,21 +914,31 @@ func (conn *Connection) newFuture(requestCode int32) (fut *Future) {
 		fut.err = ClientError{ErrConnectionClosed, "using closed connection"}
 		fut.ready = nil
 		shard.rmut.Unlock()
+		conn.stats.requestFailed(fut.err)
 		return
 	case connDisconnected:
 		fut.err = ClientError{ErrConnectionNotReady, "client connection is not ready"}
 		fut.ready = nil
 		shard.rmut.Unlock()
+		conn.stats.requestFailed(fut.err)
 		return
 	}
 	pos := (fut.requestId / conn.opts.Concurrency) & (requestsMap - 1)
 	pair := &shard.requests[pos]
 	*pair.last = fut
 	pair.last = &fut.next
-	if conn.opts.Timeout > 0 {
-		fut.timeout = time.Now().Sub(epoch) + conn.opts.Timeout
+	atomic.AddInt64(&conn.stats.inFlight, 1)
+	if !deadline.IsZero() {
+		fut.timeout = deadline.Sub(epoch)
+		heap.Push(&shard.deadlines, fut)
 	}
 	shard.rmut.Unlock()
+	if fut.timeout != 0 && int64(fut.timeout) < atomic.LoadInt64(&conn.nextTimeout) {
+		select {
+		case conn.timeoutWake <- struct{}{}:
+		default:
+		}
+	}
 	if conn.rlimit != nil && conn.opts.RLimitAction == RLimitWait {
 		select {
 		case conn.rlimit <- struct{}{}:
@@ -708,6 +957,21 @@ func (conn *Connection) newFuture(requestCode int32) (fut *Future) {
 }
 
 func (conn *Connection) putFuture(fut *Future, body func(*msgpack.Encoder) error) {
+	var packet []byte
+	if fut.icpt != nil {
+		// request is encoded before locking shard, so interceptors
+		// are not called under lock.
+		var buf smallWBuf
+		if err := fut.pack(&buf, msgpack.NewEncoder(&buf), body); err != nil {
+			conn.packFailed(fut, err)
+			return
+		}
+		if err := conn.intercept(fut, buf.Len()); err != nil {
+			fut.fail(conn, err)
+			return
+		}
+		packet = buf.b
+	}
 	shardn := fut.requestId & (conn.opts.Concurrency - 1)
 	shard := &conn.shard[shardn]
 	shard.bufmut.Lock()
@@ -722,37 +986,48 @@ func (conn *Connection) putFuture(fut *Future, body func(*msgpack.Encoder) error
 		shard.buf.b = make([]byte, 0, 128)
 		shard.enc = msgpack.NewEncoder(&shard.buf)
 	}
-	blen := shard.buf.Len()
-	if err := fut.pack(&shard.buf, shard.enc, body); err != nil {
-		shard.buf.Trunc(blen)
-		shard.bufmut.Unlock()
-		if f := conn.fetchFuture(fut.requestId); f == fut {
-			fut.markReady(conn)
-			fut.err = err
-		} else if f != nil {
-			/* in theory, it is possible. In practice, you have
-			 * to have race condition that lasts hours */
-			panic("Unknown future")
-		} else {
-			fut.wait()
-			if fut.err == nil {
-				panic("Future removed from queue without error")
-			}
-			if _, ok := fut.err.(ClientError); ok {
-				// packing error is more important than connection
-				// error, because it is indication of programmer's
-				// mistake.
-				fut.err = err
-			}
+	if packet != nil {
+		shard.buf.Write(packet)
+	} else {
+		blen := shard.buf.Len()
+		if err := fut.pack(&shard.buf, shard.enc, body); err != nil {
+			shard.buf.Trunc(blen)
+			shard.bufmut.Unlock()
+			conn.packFailed(fut, err)
+			return
 		}
-		return
 	}
+	atomic.StoreInt64(&fut.sent, int64(time.Now().Sub(epoch)))
+	conn.stats.requestSent(fut.requestCode)
 	shard.bufmut.Unlock()
 	if firstWritten {
 		conn.dirtyShard <- shardn
 	}
 }
 
+// packFailed fails future with packing error.
+func (conn *Connection) packFailed(fut *Future, err error) {
+	if f := conn.fetchFuture(fut.requestId); f == fut {
+		fut.err = err
+		fut.markReady(conn)
+	} else if f != nil {
+		/* in theory, it is possible. In practice, you have
+		 * to have race condition that lasts hours */
+		panic("Unknown future")
+	} else {
+		fut.wait()
+		if fut.err == nil {
+			panic("Future removed from queue without error")
+		}
+		if _, ok := fut.err.(ClientError); ok {
+			// packing error is more important than connection
+			// error, because it is indication of programmer's
+			// mistake.
+			fut.err = err
+		}
+	}
+}
+
 func (conn *Connection) fetchFuture(reqid uint32) (fut *Future) {
 	shard := &conn.shard[reqid&(conn.opts.Concurrency-1)]
 	shard.rmut.Lock()
@@ -778,15 +1053,22 @@ func (conn *Connection) fetchFutureImp(reqid uint32) *Future {
 			} else {
 				fut.next = nil
 			}
+			if fut.deadlinePos > 0 {
+				heap.Remove(&shard.deadlines, fut.deadlinePos-1)
+			}
 			return fut
 		}
 		root = &fut.next
 	}
 }
 
+// timeoutsIdle is a period of timeouts goroutine when there are no requests
+// with timeout.
+const timeoutsIdle = time.Hour
+
 func (conn *Connection) timeouts() {
-	timeout := conn.opts.Timeout
-	t := time.NewTimer(timeout)
+	t := time.NewTimer(timeoutsIdle)
+	var intercepted []*Future
 	for {
 		var nowepoch time.Duration
 		select {
@@ -794,43 +1076,97 @@ func (conn *Connection) timeouts() {
 			t.Stop()
 			return
 		case <-t.C:
+		case <-conn.timeoutWake:
+			if !t.Stop() {
+				select {
+				case <-t.C:
+				default:
+				}
+			}
 		}
-		minNext := time.Now().Sub(epoch) + timeout
+		// requests registered during scan wake goroutine again
+		atomic.StoreInt64(&conn.nextTimeout, math.MaxInt64)
+		minNext := time.Duration(math.MaxInt64)
 		for i := range conn.shard {
-			nowepoch = time.Now().Sub(epoch)
+			intercepted = intercepted[:0]
 			shard := &conn.shard[i]
-			for pos := range shard.requests {
-				shard.rmut.Lock()
-				pair := &shard.requests[pos]
-				for pair.first != nil && pair.first.timeout < nowepoch {
-					shard.bufmut.Lock()
-					fut := pair.first
-					pair.first = fut.next
-					if fut.next == nil {
-						pair.last = &pair.first
-					} else {
-						fut.next = nil
-					}
-					fut.err = ClientError{
-						Code: ErrTimeouted,
-						Msg:  fmt.Sprintf("client timeout for request %d", fut.requestId),
-					}
-					fut.markReady(conn)
-					shard.bufmut.Unlock()
+			shard.rmut.Lock()
+			nowepoch = time.Now().Sub(epoch)
+			for len(shard.deadlines) > 0 && shard.deadlines[0].timeout < nowepoch {
+				fut := conn.fetchFutureImp(shard.deadlines[0].requestId)
+				if fut == nil {
+					// should not happen: request is removed from heap
+					// when it is removed from shard
+					heap.Pop(&shard.deadlines)
+					continue
+				}
+				shard.bufmut.Lock()
+				fut.err = ClientError{
+					Code: ErrTimeouted,
+					Msg:  fmt.Sprintf("client timeout for request %d", fut.requestId),
 				}
-				if pair.first != nil && pair.first.timeout < minNext {
-					minNext = pair.first.timeout
+				fut.markReadyLocked(conn)
+				if fut.icpt != nil {
+					intercepted = append(intercepted, fut)
 				}
-				shard.rmut.Unlock()
+				shard.bufmut.Unlock()
+			}
+			if len(shard.deadlines) > 0 && shard.deadlines[0].timeout < minNext {
+				minNext = shard.deadlines[0].timeout
+			}
+			shard.rmut.Unlock()
+			for _, fut := range intercepted {
+				conn.complete(fut)
 			}
 		}
 		nowepoch = time.Now().Sub(epoch)
-		if nowepoch+time.Microsecond < minNext {
-			t.Reset(minNext - nowepoch)
-		} else {
-			t.Reset(time.Microsecond)
+		wait := timeoutsIdle
+		if minNext != time.Duration(math.MaxInt64) {
+			wait = minNext - nowepoch
 		}
+		if wait < time.Microsecond {
+			wait = time.Microsecond
+		}
+		atomic.StoreInt64(&conn.nextTimeout, int64(nowepoch+wait))
+		t.Reset(wait)
+	}
+}
+
+// deadlineHeap is a min-heap of requests by deadline. Position of request in
+// heap is kept in Future.deadlinePos to remove completed requests.
+type deadlineHeap []*Future
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].timeout < h[j].timeout }
+
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].deadlinePos = i + 1
+	h[j].deadlinePos = j + 1
+}
+
+func (h *deadlineHeap) Push(x interface{}) {
+	fut := x.(*Future)
+	fut.deadlinePos = len(*h) + 1
+	*h = append(*h, fut)
+}
+
+func (h *deadlineHeap) Pop() interface{} {
+	old := *h
+	fut := old[len(old)-1]
+	old[len(old)-1] = nil
+	fut.deadlinePos = 0
+	*h = old[:len(old)-1]
+	return fut
+}
+
+// reset removes all requests from heap.
+func (h *deadlineHeap) reset() {
+	for i, fut := range *h {
+		fut.deadlinePos = 0
+		(*h)[i] = nil
 	}
+	*h = (*h)[:0]
 }
 
 func write(w io.Writer, data []byte) (err error) {
@@ -878,11 +1214,37 @@ func (conn *Connection) ConfiguredTimeout() time.Duration {
 	return conn.opts.Timeout
 }
 
+// ReloadSchema loads schema from tarantool and replaces Schema of
+// the connection. Schema is also reloaded automatically after reconnect
+// unless SkipSchema option is set, but only GetSchema returns schema
+// reloaded automatically.
+// Space and index handles re-resolve their numbers after reload.
+func (conn *Connection) ReloadSchema() error {
+	if err := conn.loadSchema(); err != nil {
+		return err
+	}
+	conn.Schema = conn.GetSchema()
+	return nil
+}
+
 // OverrideSchema sets Schema for the connection
 func (conn *Connection) OverrideSchema(s *Schema) {
 	if s != nil {
-		conn.mutex.Lock()
-		defer conn.mutex.Unlock()
 		conn.Schema = s
+		conn.setSchema(s)
 	}
 }
+
+// GetSchema returns current schema of the connection. It is safe to call
+// concurrently with automatic reload after reconnect.
+func (conn *Connection) GetSchema() *Schema {
+	conn.schemaMutex.RLock()
+	defer conn.schemaMutex.RUnlock()
+	return conn.schema
+}
+
+func (conn *Connection) setSchema(s *Schema) {
+	conn.schemaMutex.Lock()
+	conn.schema = s
+	conn.schemaMutex.Unlock()
+}
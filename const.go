@@ -13,6 +13,8 @@ const (
 	Call17Request    = 10
 	PingRequest      = 64
 	SubscribeRequest = 66
+	WatchRequest     = 74
+	EventRequest     = 76
 
 	KeyCode         = 0x00
 	KeySync         = 0x01
@@ -29,6 +31,8 @@ const (
 	KeyDefTuple     = 0x28
 	KeyData         = 0x30
 	KeyError        = 0x31
+	KeyEventKey     = 0x57
+	KeyEventData    = 0x58
 
 	// https://github.com/fl00r/go-tarantool-1.6/issues/2
 
@@ -0,0 +1,128 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestRequest(t *testing.T) {
+	req := Select("customers", []Condition{{Gt, "age", 30}}, SelectOpts{
+		BaseOpts: BaseOpts{Timeout: 1500 * time.Millisecond},
+		ReadOpts: ReadOpts{Mode: ModeRead, PreferReplica: true},
+		First:    -10,
+		After:    []interface{}{1, "a"},
+	})
+	if req.Function() != "crud.select" {
+		t.Errorf("Unexpected function: %s", req.Function())
+	}
+	data, err := msgpack.Marshal(req.Args())
+	if err != nil {
+		t.Fatalf("Failed to encode args: %s", err.Error())
+	}
+	var args []interface{}
+	if err = msgpack.Unmarshal(data, &args); err != nil {
+		t.Fatalf("Failed to decode args: %s", err.Error())
+	}
+	expected := []interface{}{
+		"customers",
+		[]interface{}{[]interface{}{">", "age", uint64(30)}},
+		map[interface{}]interface{}{
+			"timeout":        1.5,
+			"mode":           "read",
+			"prefer_replica": true,
+			"first":          int64(-10),
+			"after":          []interface{}{uint64(1), "a"},
+		},
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Unexpected args: %#v", args)
+	}
+
+	// unset options are not sent
+	if opts := Insert("customers", []interface{}{1}, OperationOpts{}).Args()[2]; !reflect.DeepEqual(opts, map[string]interface{}{}) {
+		t.Errorf("Unexpected options: %#v", opts)
+	}
+	if opts := Min("customers", "age", SelectOpts{First: 1}).Args()[2]; !reflect.DeepEqual(opts, map[string]interface{}{}) {
+		t.Errorf("Unexpected options of min: %#v", opts)
+	}
+}
+
+type customer struct {
+	ID   uint64 `msgpack:"id"`
+	Name string `msgpack:"name"`
+	Age  int    `msgpack:"age"`
+}
+
+func TestDecodeResponse(t *testing.T) {
+	resp := []interface{}{
+		map[interface{}]interface{}{
+			"metadata": []interface{}{
+				map[interface{}]interface{}{"name": "id", "type": "unsigned"},
+				map[interface{}]interface{}{"name": "bucket_id", "type": "unsigned"},
+				map[interface{}]interface{}{"name": "name", "type": "string"},
+				map[interface{}]interface{}{"name": "age", "type": "number", "is_nullable": true},
+			},
+			"rows": []interface{}{
+				[]interface{}{uint64(1), uint64(477), "Elizabeth", uint64(12)},
+				[]interface{}{uint64(2), uint64(401), "Mary", uint64(46)},
+			},
+		},
+		nil,
+	}
+	result, err := decodeResponse(resp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(result.Metadata) != 4 || result.Metadata[3] != (FieldFormat{"age", "number", true}) {
+		t.Errorf("Unexpected metadata: %+v", result.Metadata)
+	}
+	var customers []customer
+	if err = result.DecodeRows(&customers); err != nil {
+		t.Fatalf("Failed to decode rows: %s", err.Error())
+	}
+	expected := []customer{{1, "Elizabeth", 12}, {2, "Mary", 46}}
+	if !reflect.DeepEqual(customers, expected) {
+		t.Errorf("Unexpected rows: %+v", customers)
+	}
+
+	result, err = decodeResponse([]interface{}{uint64(2)})
+	if err != nil || result.Value != uint64(2) {
+		t.Errorf("Unexpected result of len: %+v, %v", result, err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	_, err := decodeResponse([]interface{}{nil, map[interface{}]interface{}{
+		"class_name": "InsertError",
+		"err":        "Duplicate key exists",
+		"str":        "InsertError: Duplicate key exists",
+		"line":       uint64(120),
+	}})
+	expected := Error{
+		ClassName: "InsertError",
+		Err:       "Duplicate key exists",
+		Str:       "InsertError: Duplicate key exists",
+		Line:      120,
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+
+	result, err := decodeResponse([]interface{}{
+		map[interface{}]interface{}{"metadata": []interface{}{}, "rows": []interface{}{}},
+		[]interface{}{
+			map[interface{}]interface{}{"err": "first", "operation_data": []interface{}{uint64(1)}},
+			map[interface{}]interface{}{"err": "second"},
+		},
+	})
+	many, ok := err.(ErrorMany)
+	if result == nil || !ok || len(many.Errors) != 2 || many.Error() != "first\nsecond" {
+		t.Errorf("Unexpected result of batch: %+v, %#v", result, err)
+	}
+	if !reflect.DeepEqual(many.Errors[0].Operation, []interface{}{uint64(1)}) {
+		t.Errorf("Unexpected operation of error: %#v", many.Errors[0].Operation)
+	}
+}
@@ -0,0 +1,240 @@
+package crud
+
+import "time"
+
+// Modes of read operations.
+const (
+	ModeRead  = "read"
+	ModeWrite = "write"
+)
+
+// BaseOpts are options of all crud operations.
+type BaseOpts struct {
+	Timeout      time.Duration // timeout of vshard calls
+	VshardRouter string        // name of vshard router in multi-router setup
+}
+
+func (opts BaseOpts) toMap() map[string]interface{} {
+	ret := make(map[string]interface{})
+	if opts.Timeout != 0 {
+		ret["timeout"] = opts.Timeout.Seconds()
+	}
+	if opts.VshardRouter != "" {
+		ret["vshard_router"] = opts.VshardRouter
+	}
+	return ret
+}
+
+// ReadOpts choose instance for read operations.
+type ReadOpts struct {
+	Mode          string // ModeRead or ModeWrite
+	PreferReplica bool   // call is made on replica if possible
+	Balance       bool   // calls are balanced between replicas
+}
+
+func (opts ReadOpts) addTo(ret map[string]interface{}) {
+	if opts.Mode != "" {
+		ret["mode"] = opts.Mode
+	}
+	if opts.PreferReplica {
+		ret["prefer_replica"] = true
+	}
+	if opts.Balance {
+		ret["balance"] = true
+	}
+}
+
+// OperationOpts are options of Insert, Replace, Update, Upsert and Delete.
+type OperationOpts struct {
+	BaseOpts
+	BucketID            uint64   // bucket id is not computed by crud
+	Fields              []string // fields of returned tuple
+	Noreturn            bool     // result is not returned
+	FetchLatestMetadata bool     // metadata is fetched from storage
+}
+
+func (opts OperationOpts) toMap() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	if opts.BucketID != 0 {
+		ret["bucket_id"] = opts.BucketID
+	}
+	if len(opts.Fields) != 0 {
+		ret["fields"] = opts.Fields
+	}
+	if opts.Noreturn {
+		ret["noreturn"] = true
+	}
+	if opts.FetchLatestMetadata {
+		ret["fetch_latest_metadata"] = true
+	}
+	return ret
+}
+
+// ObjectOpts are options of InsertObject, ReplaceObject and UpsertObject.
+type ObjectOpts struct {
+	OperationOpts
+	SkipNullabilityCheckOnFlatten bool // nil values of non-nullable fields are allowed
+}
+
+func (opts ObjectOpts) toMap() map[string]interface{} {
+	ret := opts.OperationOpts.toMap()
+	if opts.SkipNullabilityCheckOnFlatten {
+		ret["skip_nullability_check_on_flatten"] = true
+	}
+	return ret
+}
+
+// ManyOpts are options of batch operations.
+type ManyOpts struct {
+	BaseOpts
+	Fields              []string // fields of returned tuples
+	StopOnError         bool     // batch is stopped on first error
+	RollbackOnError     bool     // tuples of storage are rolled back on error
+	Noreturn            bool     // result is not returned
+	FetchLatestMetadata bool     // metadata is fetched from storage
+}
+
+func (opts ManyOpts) toMap() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	if len(opts.Fields) != 0 {
+		ret["fields"] = opts.Fields
+	}
+	if opts.StopOnError {
+		ret["stop_on_error"] = true
+	}
+	if opts.RollbackOnError {
+		ret["rollback_on_error"] = true
+	}
+	if opts.Noreturn {
+		ret["noreturn"] = true
+	}
+	if opts.FetchLatestMetadata {
+		ret["fetch_latest_metadata"] = true
+	}
+	return ret
+}
+
+// ObjectManyOpts are options of batch operations with objects.
+type ObjectManyOpts struct {
+	ManyOpts
+	SkipNullabilityCheckOnFlatten bool // nil values of non-nullable fields are allowed
+}
+
+func (opts ObjectManyOpts) toMap() map[string]interface{} {
+	ret := opts.ManyOpts.toMap()
+	if opts.SkipNullabilityCheckOnFlatten {
+		ret["skip_nullability_check_on_flatten"] = true
+	}
+	return ret
+}
+
+// GetOpts are options of Get.
+type GetOpts struct {
+	BaseOpts
+	ReadOpts
+	BucketID            uint64   // bucket id is not computed by crud
+	Fields              []string // fields of returned tuple
+	FetchLatestMetadata bool     // metadata is fetched from storage
+}
+
+func (opts GetOpts) toMap() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	opts.ReadOpts.addTo(ret)
+	if opts.BucketID != 0 {
+		ret["bucket_id"] = opts.BucketID
+	}
+	if len(opts.Fields) != 0 {
+		ret["fields"] = opts.Fields
+	}
+	if opts.FetchLatestMetadata {
+		ret["fetch_latest_metadata"] = true
+	}
+	return ret
+}
+
+// SelectOpts are options of Select, Min and Max. Pagination options
+// (First, After, BatchSize, Fullscan and YieldEvery) are used by Select
+// only.
+type SelectOpts struct {
+	BaseOpts
+	ReadOpts
+	BucketID uint64   // bucket id is not computed by crud
+	Fields   []string // fields of returned tuples
+	// First is a maximum number of returned tuples. Tuples are returned in
+	// reverse order if it is negative.
+	First int64
+	// After is a tuple, which is returned before first tuple of result.
+	// It is used for pagination.
+	After               interface{}
+	BatchSize           uint // number of tuples fetched from storage at once
+	Fullscan            bool // full scan is allowed
+	YieldEvery          uint // number of scanned tuples between yields
+	FetchLatestMetadata bool // metadata is fetched from storage
+}
+
+func (opts SelectOpts) toMap() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	opts.ReadOpts.addTo(ret)
+	if opts.BucketID != 0 {
+		ret["bucket_id"] = opts.BucketID
+	}
+	if len(opts.Fields) != 0 {
+		ret["fields"] = opts.Fields
+	}
+	if opts.First != 0 {
+		ret["first"] = opts.First
+	}
+	if opts.After != nil {
+		ret["after"] = opts.After
+	}
+	if opts.BatchSize != 0 {
+		ret["batch_size"] = opts.BatchSize
+	}
+	if opts.Fullscan {
+		ret["fullscan"] = true
+	}
+	if opts.YieldEvery != 0 {
+		ret["yield_every"] = opts.YieldEvery
+	}
+	if opts.FetchLatestMetadata {
+		ret["fetch_latest_metadata"] = true
+	}
+	return ret
+}
+
+// minMaxOpts returns options of Min and Max, which support no pagination.
+func (opts SelectOpts) minMaxOpts() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	opts.ReadOpts.addTo(ret)
+	if opts.BucketID != 0 {
+		ret["bucket_id"] = opts.BucketID
+	}
+	if len(opts.Fields) != 0 {
+		ret["fields"] = opts.Fields
+	}
+	return ret
+}
+
+// CountOpts are options of Count.
+type CountOpts struct {
+	BaseOpts
+	ReadOpts
+	BucketID   uint64 // bucket id is not computed by crud
+	Fullscan   bool   // full scan is allowed
+	YieldEvery uint   // number of scanned tuples between yields
+}
+
+func (opts CountOpts) toMap() map[string]interface{} {
+	ret := opts.BaseOpts.toMap()
+	opts.ReadOpts.addTo(ret)
+	if opts.BucketID != 0 {
+		ret["bucket_id"] = opts.BucketID
+	}
+	if opts.Fullscan {
+		ret["fullscan"] = true
+	}
+	if opts.YieldEvery != 0 {
+		ret["yield_every"] = opts.YieldEvery
+	}
+	return ret
+}
@@ -0,0 +1,216 @@
+package crud
+
+import (
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// Operators of conditions.
+const (
+	Eq = "=="
+	Lt = "<"
+	Le = "<="
+	Gt = ">"
+	Ge = ">="
+)
+
+// Condition is a condition of Select and Count: Field (name of field or
+// index) compared with Value by Operator.
+type Condition struct {
+	Operator string
+	Field    string
+	Value    interface{}
+}
+
+func (c Condition) EncodeMsgpack(e *msgpack.Encoder) error {
+	if err := e.EncodeSliceLen(3); err != nil {
+		return err
+	}
+	if err := e.EncodeString(c.Operator); err != nil {
+		return err
+	}
+	if err := e.EncodeString(c.Field); err != nil {
+		return err
+	}
+	return e.Encode(c.Value)
+}
+
+// Operation is an update operation as in box update: Operator ("=", "+",
+// "-", "&", "|", "^", "!", "#" or ":") applied to Field (name or number)
+// with Value.
+type Operation struct {
+	Operator string
+	Field    interface{}
+	Value    interface{}
+}
+
+func (o Operation) EncodeMsgpack(e *msgpack.Encoder) error {
+	if err := e.EncodeSliceLen(3); err != nil {
+		return err
+	}
+	if err := e.EncodeString(o.Operator); err != nil {
+		return err
+	}
+	if err := e.Encode(o.Field); err != nil {
+		return err
+	}
+	return e.Encode(o.Value)
+}
+
+// Request is a call of crud function made by one of request functions.
+type Request struct {
+	function string
+	args     []interface{}
+}
+
+// Function returns name of crud function.
+func (req Request) Function() string {
+	return req.function
+}
+
+// Args returns arguments of crud function.
+func (req Request) Args() []interface{} {
+	return req.args
+}
+
+func request(function string, args ...interface{}) Request {
+	return Request{function: "crud." + function, args: args}
+}
+
+// Insert returns request, which inserts tuple to space.
+func Insert(space string, tuple interface{}, opts OperationOpts) Request {
+	return request("insert", space, tuple, opts.toMap())
+}
+
+// InsertObject returns request, which inserts object (map of field names
+// to values) to space.
+func InsertObject(space string, object interface{}, opts ObjectOpts) Request {
+	return request("insert_object", space, object, opts.toMap())
+}
+
+// InsertMany returns request, which inserts tuples to space.
+func InsertMany(space string, tuples interface{}, opts ManyOpts) Request {
+	return request("insert_many", space, tuples, opts.toMap())
+}
+
+// InsertObjectMany returns request, which inserts objects to space.
+func InsertObjectMany(space string, objects interface{}, opts ObjectManyOpts) Request {
+	return request("insert_object_many", space, objects, opts.toMap())
+}
+
+// Get returns request, which gets tuple by primary key.
+func Get(space string, key interface{}, opts GetOpts) Request {
+	return request("get", space, key, opts.toMap())
+}
+
+// Update returns request, which updates tuple by primary key.
+func Update(space string, key interface{}, operations []Operation, opts OperationOpts) Request {
+	return request("update", space, key, operations, opts.toMap())
+}
+
+// Delete returns request, which deletes tuple by primary key.
+func Delete(space string, key interface{}, opts OperationOpts) Request {
+	return request("delete", space, key, opts.toMap())
+}
+
+// Replace returns request, which inserts or replaces tuple.
+func Replace(space string, tuple interface{}, opts OperationOpts) Request {
+	return request("replace", space, tuple, opts.toMap())
+}
+
+// ReplaceObject returns request, which inserts or replaces object.
+func ReplaceObject(space string, object interface{}, opts ObjectOpts) Request {
+	return request("replace_object", space, object, opts.toMap())
+}
+
+// ReplaceMany returns request, which inserts or replaces tuples.
+func ReplaceMany(space string, tuples interface{}, opts ManyOpts) Request {
+	return request("replace_many", space, tuples, opts.toMap())
+}
+
+// ReplaceObjectMany returns request, which inserts or replaces objects.
+func ReplaceObjectMany(space string, objects interface{}, opts ObjectManyOpts) Request {
+	return request("replace_object_many", space, objects, opts.toMap())
+}
+
+// Upsert returns request, which inserts tuple or updates existing one with
+// operations.
+func Upsert(space string, tuple interface{}, operations []Operation, opts OperationOpts) Request {
+	return request("upsert", space, tuple, operations, opts.toMap())
+}
+
+// UpsertObject returns request, which inserts object or updates existing
+// tuple with operations.
+func UpsertObject(space string, object interface{}, operations []Operation, opts ObjectOpts) Request {
+	return request("upsert_object", space, object, operations, opts.toMap())
+}
+
+// TupleOperations is a tuple and operations of UpsertMany.
+type TupleOperations struct {
+	Tuple      interface{}
+	Operations []Operation
+}
+
+func (t TupleOperations) EncodeMsgpack(e *msgpack.Encoder) error {
+	if err := e.EncodeSliceLen(2); err != nil {
+		return err
+	}
+	if err := e.Encode(t.Tuple); err != nil {
+		return err
+	}
+	return e.Encode(t.Operations)
+}
+
+// UpsertMany returns request, which upserts tuples with operations.
+func UpsertMany(space string, tuples []TupleOperations, opts ManyOpts) Request {
+	return request("upsert_many", space, tuples, opts.toMap())
+}
+
+// UpsertObjectMany returns request, which upserts objects with operations.
+// Tuple of TupleOperations is an object.
+func UpsertObjectMany(space string, objects []TupleOperations, opts ObjectManyOpts) Request {
+	return request("upsert_object_many", space, objects, opts.toMap())
+}
+
+// Select returns request, which selects tuples matching conditions.
+func Select(space string, conditions []Condition, opts SelectOpts) Request {
+	return request("select", space, conditions, opts.toMap())
+}
+
+// Min returns request, which gets tuple with minimal value of index.
+func Min(space string, index string, opts SelectOpts) Request {
+	return request("min", space, index, opts.minMaxOpts())
+}
+
+// Max returns request, which gets tuple with maximal value of index.
+func Max(space string, index string, opts SelectOpts) Request {
+	return request("max", space, index, opts.minMaxOpts())
+}
+
+// Truncate returns request, which truncates space.
+func Truncate(space string, opts BaseOpts) Request {
+	return request("truncate", space, opts.toMap())
+}
+
+// Len returns request, which gets number of tuples in space.
+func Len(space string, opts BaseOpts) Request {
+	return request("len", space, opts.toMap())
+}
+
+// Count returns request, which counts tuples matching conditions.
+func Count(space string, conditions []Condition, opts CountOpts) Request {
+	return request("count", space, conditions, opts.toMap())
+}
+
+// StorageInfo returns request, which gets statuses of storages.
+func StorageInfo(opts BaseOpts) Request {
+	return request("storage_info", opts.toMap())
+}
+
+// Stats returns request, which gets statistics of space or of all spaces
+// if space is empty.
+func Stats(space string) Request {
+	if space == "" {
+		return request("stats")
+	}
+	return request("stats", space)
+}
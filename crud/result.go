@@ -0,0 +1,165 @@
+package crud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tarantool/go-tarantool"
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// FieldFormat is a format of field from result metadata.
+type FieldFormat struct {
+	Name       string
+	Type       string
+	IsNullable bool
+}
+
+// Result is a result of crud operation.
+type Result struct {
+	// Metadata is a format of rows. It is set for operations, which return
+	// tuples.
+	Metadata []FieldFormat
+	// Rows are tuples returned by operation.
+	Rows [][]interface{}
+	// Value is a result of operations, which return no tuples: number of
+	// tuples for Len and Count, true for Truncate, statuses of storages
+	// for StorageInfo and statistics for Stats.
+	Value interface{}
+}
+
+// DecodeRows decodes rows to slice of structs (or maps) pointed by result.
+// Fields of row are mapped to struct fields by names from metadata, as
+// msgpack does for maps: by `msgpack` tag or name of struct field.
+func (r *Result) DecodeRows(result interface{}) error {
+	objects := make([]map[string]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		object := make(map[string]interface{}, len(r.Metadata))
+		for j, field := range r.Metadata {
+			if j < len(row) {
+				object[field.Name] = row[j]
+			}
+		}
+		objects[i] = object
+	}
+	data, err := msgpack.Marshal(objects)
+	if err != nil {
+		return err
+	}
+	return msgpack.Unmarshal(data, result)
+}
+
+// Error is an error returned by crud.
+type Error struct {
+	ClassName string
+	Err       string
+	File      string
+	Line      uint64
+	Stack     string
+	Str       string
+	// Operation is a tuple or object, which batch operation failed on.
+	Operation interface{}
+}
+
+// Error converts Error to string.
+func (e Error) Error() string {
+	if e.Str != "" {
+		return e.Str
+	}
+	return e.Err
+}
+
+// ErrorMany is a list of errors of batch operation.
+type ErrorMany struct {
+	Errors []Error
+}
+
+// Error converts ErrorMany to string.
+func (e ErrorMany) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Do sends request and decodes its result. Batch operations could return
+// both result of succeeded tuples and ErrorMany.
+func Do(conn tarantool.Connector, req Request) (*Result, error) {
+	var resp []interface{}
+	if err := conn.Call17Typed(req.function, req.args, &resp); err != nil {
+		return nil, err
+	}
+	return decodeResponse(resp)
+}
+
+func decodeResponse(resp []interface{}) (*Result, error) {
+	var result *Result
+	if len(resp) > 0 && resp[0] != nil {
+		result = decodeResult(resp[0])
+	}
+	if len(resp) > 1 && resp[1] != nil {
+		return result, decodeError(resp[1])
+	}
+	return result, nil
+}
+
+func decodeResult(v interface{}) *Result {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return &Result{Value: v}
+	}
+	metadata, hasMetadata := m["metadata"].([]interface{})
+	rows, hasRows := m["rows"].([]interface{})
+	if !hasMetadata && !hasRows {
+		return &Result{Value: v}
+	}
+	result := &Result{
+		Metadata: make([]FieldFormat, 0, len(metadata)),
+		Rows:     make([][]interface{}, 0, len(rows)),
+	}
+	for _, f := range metadata {
+		field, _ := f.(map[interface{}]interface{})
+		format := FieldFormat{}
+		format.Name, _ = field["name"].(string)
+		format.Type, _ = field["type"].(string)
+		format.IsNullable, _ = field["is_nullable"].(bool)
+		result.Metadata = append(result.Metadata, format)
+	}
+	for _, r := range rows {
+		row, _ := r.([]interface{})
+		result.Rows = append(result.Rows, row)
+	}
+	return result
+}
+
+func decodeError(v interface{}) error {
+	if errs, ok := v.([]interface{}); ok {
+		many := ErrorMany{Errors: make([]Error, 0, len(errs))}
+		for _, e := range errs {
+			many.Errors = append(many.Errors, newError(e))
+		}
+		return many
+	}
+	return newError(v)
+}
+
+func newError(v interface{}) Error {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return Error{Err: fmt.Sprint(v)}
+	}
+	e := Error{Operation: m["operation_data"]}
+	e.ClassName, _ = m["class_name"].(string)
+	e.Err, _ = m["err"].(string)
+	e.File, _ = m["file"].(string)
+	e.Stack, _ = m["stack"].(string)
+	e.Str, _ = m["str"].(string)
+	switch line := m["line"].(type) {
+	case uint64:
+		e.Line = line
+	case int64:
+		e.Line = uint64(line)
+	}
+	return e
+}
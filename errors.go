@@ -39,6 +39,16 @@ func (clierr ClientError) Temporary() bool {
 	}
 }
 
+// SchemaError is returned when schema could not be loaded from tarantool,
+// or when space or index could not be resolved with schema.
+type SchemaError struct {
+	Msg string
+}
+
+func (schemaerr SchemaError) Error() string {
+	return schemaerr.Msg
+}
+
 // Tarantool client error codes
 const (
 	ErrConnectionNotReady = 0x4000 + iota
@@ -0,0 +1,216 @@
+package tarantool
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// shutdownEventKey is a key of event broadcasted by tarantool 2.10+ on
+// graceful shutdown.
+const shutdownEventKey = "box.shutdown"
+
+// eventQueue delivers events to subscriber in order from separate goroutine.
+type eventQueue struct {
+	handler func(ConnEvent)
+	mutex   sync.Mutex
+	events  []ConnEvent
+	wake    chan struct{}
+	stop    chan struct{}
+}
+
+func newEventQueue(handler func(ConnEvent)) *eventQueue {
+	q := &eventQueue{
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *eventQueue) push(event ConnEvent) {
+	q.mutex.Lock()
+	q.events = append(q.events, event)
+	q.mutex.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *eventQueue) pop() (event ConnEvent, ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.events) == 0 {
+		return
+	}
+	event = q.events[0]
+	q.events[0] = ConnEvent{}
+	q.events = q.events[1:]
+	return event, true
+}
+
+func (q *eventQueue) run() {
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		}
+		for {
+			event, ok := q.pop()
+			if !ok {
+				break
+			}
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+			q.handler(event)
+			if event.Kind == Closed {
+				// there are no events after Closed
+				return
+			}
+		}
+	}
+}
+
+// OnEvent subscribes handler to events of connection and returns function,
+// which unsubscribes it.
+//
+// Unlike Notify channel, no events are dropped: they are queued and passed
+// to handler in order they happened from separate goroutine, so slow
+// handler does not block connection. Closed event is the last one. Use
+// Opts.OnEvent to receive events happened during Connect.
+func (conn *Connection) OnEvent(handler func(ConnEvent)) (unsubscribe func()) {
+	q := conn.subscribe(handler)
+	if q == nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { conn.unsubscribe(q) })
+	}
+}
+
+func (conn *Connection) subscribe(handler func(ConnEvent)) *eventQueue {
+	conn.subMutex.Lock()
+	defer conn.subMutex.Unlock()
+	if conn.eventsDone {
+		return nil
+	}
+	q := newEventQueue(handler)
+	conn.subscribers = append(conn.subscribers, q)
+	return q
+}
+
+func (conn *Connection) unsubscribe(q *eventQueue) {
+	conn.subMutex.Lock()
+	defer conn.subMutex.Unlock()
+	for i, s := range conn.subscribers {
+		if s == q {
+			conn.subscribers = append(conn.subscribers[:i], conn.subscribers[i+1:]...)
+			break
+		}
+	}
+	close(q.stop)
+}
+
+// publish passes event to subscribers.
+func (conn *Connection) publish(event ConnEvent) {
+	conn.subMutex.Lock()
+	defer conn.subMutex.Unlock()
+	if conn.eventsDone {
+		return
+	}
+	for _, q := range conn.subscribers {
+		q.push(event)
+	}
+	if event.Kind == Closed {
+		conn.eventsDone = true
+		conn.subscribers = nil
+	}
+}
+
+// stopEvents stops delivery of events to subscribers of connection,
+// which failed to connect.
+func (conn *Connection) stopEvents() {
+	conn.subMutex.Lock()
+	defer conn.subMutex.Unlock()
+	conn.eventsDone = true
+	for _, q := range conn.subscribers {
+		close(q.stop)
+	}
+	conn.subscribers = nil
+}
+
+// supportsWatchers reports if tarantool from greeting broadcasts events
+// (tarantool 2.10+).
+func (conn *Connection) supportsWatchers() bool {
+	var major, minor int
+	if _, err := fmt.Sscanf(conn.Greeting.Version, "Tarantool %d.%d", &major, &minor); err != nil {
+		return false
+	}
+	return major > 2 || major == 2 && minor >= 10
+}
+
+// watch sends request to receive event with key. Tarantool sends current
+// value of key at once and next one after request is repeated.
+func (conn *Connection) watch(key string) {
+	fut := &Future{requestCode: WatchRequest}
+	shard := &conn.shard[0]
+	shard.bufmut.Lock()
+	firstWritten := shard.buf.Len() == 0
+	if shard.buf.Cap() == 0 {
+		shard.buf.b = make([]byte, 0, 128)
+		shard.enc = msgpack.NewEncoder(&shard.buf)
+	}
+	blen := shard.buf.Len()
+	err := fut.pack(&shard.buf, shard.enc, func(enc *msgpack.Encoder) error {
+		enc.EncodeMapLen(1)
+		enc.EncodeUint64(KeyEventKey)
+		return enc.EncodeString(key)
+	})
+	if err != nil {
+		shard.buf.Trunc(blen)
+	}
+	shard.bufmut.Unlock()
+	if err == nil && firstWritten {
+		conn.dirtyShard <- 0
+	}
+}
+
+// handleEvent processes event broadcasted by tarantool.
+func (conn *Connection) handleEvent(resp *Response) {
+	var key string
+	var data interface{}
+	d := msgpack.NewDecoder(&resp.buf)
+	l, err := d.DecodeMapLen()
+	for ; err == nil && l > 0; l-- {
+		var cd int
+		if cd, err = resp.smallInt(d); err != nil {
+			break
+		}
+		switch cd {
+		case KeyEventKey:
+			key, err = d.DecodeString()
+		case KeyEventData:
+			data, err = d.DecodeInterface()
+		default:
+			err = d.Skip()
+		}
+	}
+	if err != nil || key != shutdownEventKey {
+		return
+	}
+	if shutdown, _ := data.(bool); shutdown {
+		conn.log(LogLevelWarn, LogShutdownRequested, "server is shutting down")
+		conn.notify(ShutdownRequested)
+		return
+	}
+	// acknowledge initial value to receive next one
+	conn.watch(key)
+}
@@ -0,0 +1,147 @@
+package tarantool_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/tarantool/go-tarantool"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestOnEventOrder(t *testing.T) {
+	// address with no listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err.Error())
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	events := make(chan ConnEvent, 16)
+	conn, err := Connect(addr, Opts{
+		SkipSchema:    true,
+		Reconnect:     time.Millisecond,
+		MaxReconnects: 5,
+		Logger:        &recordingLogger{},
+		OnEvent: func(event ConnEvent) {
+			// slow handler does not lose events
+			time.Sleep(10 * time.Millisecond)
+			events <- event
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect with reconnects: %s", err.Error())
+	}
+	defer conn.Close()
+
+	for attempt := uint(0); attempt <= 5; attempt++ {
+		select {
+		case event := <-events:
+			if event.Kind != ReconnectFailed || event.Attempt != attempt {
+				t.Fatalf("Unexpected event: %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("ReconnectFailed event %d is not received", attempt)
+		}
+	}
+	select {
+	case event := <-events:
+		if event.Kind != Closed {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Closed event is not received")
+	}
+
+	// subscription to closed connection receives nothing
+	conn.OnEvent(func(event ConnEvent) {
+		t.Errorf("Unexpected event after Closed: %+v", event)
+	})()
+}
+
+// serveShutdown accepts one connection as Tarantool 2.10 does and sends
+// box.shutdown event after watch request is received.
+func serveShutdown(l net.Listener) {
+	c, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	greeting := make([]byte, 128)
+	copy(greeting, "Tarantool 2.10.0 (Binary) 00000000-0000-0000-0000-000000000000")
+	copy(greeting[64:], "QK2HoFZGXTXBq2vFj7soCsHqTo6PGTF575ssUBAJLAI=")
+	if _, err = c.Write(greeting); err != nil {
+		return
+	}
+	for {
+		var length [PacketLengthBytes]byte
+		if _, err = io.ReadFull(c, length[:]); err != nil {
+			return
+		}
+		packet := make([]byte, binary.BigEndian.Uint32(length[1:]))
+		if _, err = io.ReadFull(c, packet); err != nil {
+			return
+		}
+		var header map[int]interface{}
+		if err = msgpack.NewDecoder(bytes.NewReader(packet)).Decode(&header); err != nil {
+			return
+		}
+		if code, _ := header[KeyCode].(uint64); code != WatchRequest {
+			continue
+		}
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.Encode(map[int]interface{}{KeyCode: EventRequest, KeySync: 0})
+		enc.Encode(map[int]interface{}{KeyEventKey: "box.shutdown", KeyEventData: true})
+		binary.BigEndian.PutUint32(length[1:], uint32(buf.Len()))
+		length[0] = 0xce
+		c.Write(append(length[:], buf.Bytes()...))
+	}
+}
+
+func TestShutdownRequested(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err.Error())
+	}
+	defer l.Close()
+	go serveShutdown(l)
+
+	events := make(chan ConnEvent, 16)
+	conn, err := Connect(l.Addr().String(), Opts{
+		SkipSchema: true,
+		Logger:     &recordingLogger{},
+		OnEvent: func(event ConnEvent) {
+			events <- event
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	for _, kind := range []ConnEventKind{Connected, ShutdownRequested} {
+		select {
+		case event := <-events:
+			if event.Kind != kind {
+				t.Errorf("Unexpected event %+v, expected kind %d", event, kind)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Event %d is not received", kind)
+		}
+	}
+}
+
+func TestKindValues(t *testing.T) {
+	// values of kinds existed before are kept
+	if Connected != 1 || Closed != 4 || SchemaReloaded != 5 || ShutdownRequested != 6 {
+		t.Errorf("Unexpected event kinds: %d %d %d %d", Connected, Closed, SchemaReloaded, ShutdownRequested)
+	}
+	if LogReconnectFailed != 5 || LogUnexpectedResultId != 7 || LogConnected != 8 {
+		t.Errorf("Unexpected log kinds: %d %d %d", LogReconnectFailed, LogUnexpectedResultId, LogConnected)
+	}
+}
@@ -1,5 +1,68 @@
 package tarantool
 
+import (
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
 func (schema *Schema) ResolveSpaceIndex(s interface{}, i interface{}) (spaceNo, indexNo uint32, err error) {
 	return schema.resolveSpaceIndex(s, i)
 }
+
+// SupportsHints reports if tarantool with greeting version supports hints.
+func SupportsHints(greeting string) bool {
+	return supportsHints(greeting)
+}
+
+// DecodeSpaces decodes msgpack encoded array of _vspace tuples.
+func DecodeSpaces(b []byte, collations map[uint32]string) ([]*Space, error) {
+	s := schemaSpaces{collations: collations}
+	err := msgpack.Unmarshal(b, &s)
+	return s.spaces, err
+}
+
+// DecodeIndexes decodes msgpack encoded array of _vindex tuples loaded from
+// tarantool with or without hints support. It returns decoded indexes and
+// numbers of their spaces.
+func DecodeIndexes(b []byte, collations map[uint32]string, hints bool) ([]*Index, []uint32, error) {
+	s := schemaIndexes{collations: collations, hints: hints}
+	err := msgpack.Unmarshal(b, &s)
+	return s.indexes, s.spaceIds, err
+}
+
+// DecodeSequences decodes msgpack encoded array of _vsequence tuples.
+func DecodeSequences(b []byte) ([]*Sequence, error) {
+	s := schemaSequences{}
+	err := msgpack.Unmarshal(b, &s)
+	return s.sequences, err
+}
+
+// DecodeFunctions decodes msgpack encoded array of _vfunc tuples.
+func DecodeFunctions(b []byte) ([]*Function, error) {
+	s := schemaFunctions{}
+	err := msgpack.Unmarshal(b, &s)
+	return s.functions, err
+}
+
+// InterceptCompleted passes request, which is completed before it is sent
+// (ie timeouted), to interceptors.
+func InterceptCompleted(interceptors []Interceptor) error {
+	conn := &Connection{opts: Opts{Interceptors: interceptors}}
+	fut := &Future{requestCode: PingRequest, err: ClientError{ErrTimeouted, "request timeout"}}
+	fut.icpt = conn.newIntercepted(nil, fut)
+	conn.complete(fut)
+	return conn.intercept(fut, 0)
+}
+
+func (conn *Connection) LogRateLimited(requestCode int32) {
+	conn.logRateLimited(requestCode)
+}
+
+// TokensAfterCancel returns number of tokens in full bucket after reserved
+// token is returned twice.
+func TokensAfterCancel(rate RequestRate) float64 {
+	b, _ := newTokenBucket(rate)
+	b.reserve(b.last, 0)
+	b.cancel()
+	b.cancel()
+	return b.tokens
+}
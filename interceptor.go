@@ -0,0 +1,164 @@
+package tarantool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes request passed to interceptors.
+type RequestInfo struct {
+	// Context is context of request (see Connection.WithContext).
+	// BeforeSend could replace it to pass values to OnComplete.
+	Context context.Context
+	// Addr is configured address of connection.
+	Addr string
+	// RemoteAddr is address of Tarantool socket.
+	RemoteAddr string
+	// Code is request code (SelectRequest, InsertRequest, ...).
+	Code int32
+	// RequestId is id of request within connection.
+	RequestId uint32
+	// Space and Index are space and index as they were passed to request
+	// method. They are nil for requests not related to spaces.
+	Space interface{}
+	Index interface{}
+	// SpaceNo and IndexNo are resolved numbers of space and index.
+	SpaceNo uint32
+	IndexNo uint32
+	// SpaceName and IndexName are names of space and index found in
+	// Schema, they are empty if schema is not loaded.
+	SpaceName string
+	IndexName string
+	// FunctionName is name of called function for Call and Call17 requests.
+	FunctionName string
+	// Expression is lua expression of Eval request.
+	Expression string
+	// Size is size of encoded request in bytes.
+	Size int
+}
+
+// Interceptor observes requests of Connection. Both functions are optional.
+//
+// Interceptors are called in order they are specified in Opts.Interceptors
+// before request is sent, and in reverse order on completion. OnComplete is
+// called only for interceptors, which BeforeSend was called (or which have
+// no BeforeSend). Request timeouted before it is passed to interceptors is
+// not seen by them at all.
+//
+// Functions are called from goroutines of connection, so they should not
+// block. They may issue new requests on the connection. OnComplete is called
+// before Future becomes ready, except for requests failed due to timeout or
+// closed connection: those become ready first.
+type Interceptor struct {
+	// BeforeSend is called when request is encoded and is about to be sent.
+	// If it returns error, request is not sent and fails with the error.
+	BeforeSend func(info *RequestInfo) error
+	// OnComplete is called when request is completed. resp is nil if
+	// request failed on client side with err. Errors returned by Tarantool
+	// are not decoded yet, resp.Code should be checked for them.
+	// latency is zero for requests which were not sent.
+	OnComplete func(info *RequestInfo, resp *Response, err error, latency time.Duration)
+}
+
+// intercepted is a state of request passed to interceptors.
+type intercepted struct {
+	info RequestInfo
+	// mutex serializes BeforeSend and OnComplete calls: request could be
+	// timeouted while it is passed to interceptors.
+	mutex sync.Mutex
+	// seen is a number of interceptors, which have seen request.
+	seen int
+	// completed is set when OnComplete are called, BeforeSend is not
+	// called after that.
+	completed bool
+}
+
+func (conn *Connection) newIntercepted(scope *requestScope, fut *Future) *intercepted {
+	icpt := &intercepted{info: RequestInfo{
+		Context:   context.Background(),
+		Addr:      conn.addr,
+		Code:      fut.requestCode,
+		RequestId: fut.requestId,
+	}}
+	if scope != nil && scope.ctx != nil {
+		icpt.info.Context = scope.ctx
+	}
+	if addr, ok := conn.remoteAddr.Load().(string); ok {
+		icpt.info.RemoteAddr = addr
+	}
+	return icpt
+}
+
+// describeSpace fills space related fields of request info.
+func (fut *Future) describeSpace(schema *Schema, space, index interface{}, spaceNo, indexNo uint32) {
+	if fut.icpt == nil {
+		return
+	}
+	info := &fut.icpt.info
+	info.Space = space
+	info.Index = index
+	info.SpaceNo = spaceNo
+	info.IndexNo = indexNo
+	if schema == nil {
+		return
+	}
+	if s, ok := schema.SpacesById[spaceNo]; ok {
+		info.SpaceName = s.Name
+		if index != nil {
+			if i, ok := s.IndexesById[indexNo]; ok {
+				info.IndexName = i.Name
+			}
+		}
+	}
+}
+
+// describeCall fills function name or expression of request info.
+func (fut *Future) describeCall(functionName, expr string) {
+	if fut.icpt != nil {
+		fut.icpt.info.FunctionName = functionName
+		fut.icpt.info.Expression = expr
+	}
+}
+
+// intercept passes encoded request to interceptors.
+func (conn *Connection) intercept(fut *Future, size int) error {
+	icpt := fut.icpt
+	icpt.mutex.Lock()
+	defer icpt.mutex.Unlock()
+	if icpt.completed {
+		// request is timeouted before it is passed to interceptors
+		return nil
+	}
+	icpt.info.Size = size
+	for _, interceptor := range conn.opts.Interceptors {
+		icpt.seen++
+		if interceptor.BeforeSend == nil {
+			continue
+		}
+		if err := interceptor.BeforeSend(&icpt.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// complete passes completed request to interceptors.
+func (conn *Connection) complete(fut *Future) {
+	var latency time.Duration
+	if sent := fut.sentAt(); sent > 0 {
+		latency = time.Now().Sub(epoch) - sent
+	}
+	icpt := fut.icpt
+	icpt.mutex.Lock()
+	defer icpt.mutex.Unlock()
+	if icpt.completed {
+		return
+	}
+	icpt.completed = true
+	for i := icpt.seen - 1; i >= 0; i-- {
+		if onComplete := conn.opts.Interceptors[i].OnComplete; onComplete != nil {
+			onComplete(&icpt.info, fut.resp, fut.err, latency)
+		}
+	}
+}
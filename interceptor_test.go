@@ -0,0 +1,27 @@
+package tarantool_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestInterceptorTimeoutedBeforeSend(t *testing.T) {
+	var beforeSend, onComplete int
+	interceptors := []Interceptor{{
+		BeforeSend: func(info *RequestInfo) error {
+			beforeSend++
+			return nil
+		},
+		OnComplete: func(info *RequestInfo, resp *Response, err error, latency time.Duration) {
+			onComplete++
+		},
+	}}
+	if err := InterceptCompleted(interceptors); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if beforeSend != 0 || onComplete != 0 {
+		t.Errorf("Unpaired interceptor calls: BeforeSend %d, OnComplete %d", beforeSend, onComplete)
+	}
+}
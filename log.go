@@ -0,0 +1,188 @@
+package tarantool
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevel is a level of structured log record.
+// Values match levels of log/slog package.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = -4
+	LogLevelInfo  LogLevel = 0
+	LogLevelWarn  LogLevel = 4
+	LogLevelError LogLevel = 8
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+var logKindNames = map[ConnLogKind]string{
+	LogReconnectFailed:     "reconnect_failed",
+	LogLastReconnectFailed: "last_reconnect_failed",
+	LogUnexpectedResultId:  "unexpected_result_id",
+	LogConnected:           "connected",
+	LogAuthenticated:       "authenticated",
+	LogSchemaLoaded:        "schema_loaded",
+	LogSchemaLoadFailed:    "schema_load_failed",
+	LogReconnecting:        "reconnecting",
+	LogRateLimited:         "rate_limited",
+	LogDisconnected:        "disconnected",
+	LogClosed:              "closed",
+	LogShutdownRequested:   "shutdown_requested",
+}
+
+func (k ConnLogKind) String() string {
+	if name, ok := logKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("event(%d)", int(k))
+}
+
+// LogField is a key-value pair of structured log record.
+//
+// Fields used by Connection:
+//   - "error" (error): error caused event;
+//   - "attempt" (uint), "max_attempts" (uint), "delay" (time.Duration):
+//     reconnect attempt number, limit of attempts and pause before next one;
+//   - "response" (*Response): unexpected response;
+//   - "remote_addr" (string): address of Tarantool socket;
+//   - "user" (string): authenticated user;
+//   - "spaces" (int), "duration" (time.Duration): number of loaded spaces and
+//     time spent on schema loading;
+//   - "request_code" (int32), "count" (uint64): code of last rate limited
+//     request and number of requests limited since previous record.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// StructuredLogger is a logger receiving events of Connection as structured
+// records. It is specified in Opts.StructuredLogger.
+type StructuredLogger interface {
+	Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField)
+}
+
+// AdaptLogger returns StructuredLogger, which passes events to Logger as
+// they were passed before structured logging was introduced. Only
+// LogReconnectFailed, LogLastReconnectFailed and LogUnexpectedResultId
+// events are passed, others are dropped.
+func AdaptLogger(logger Logger) StructuredLogger {
+	return legacyLogger{logger}
+}
+
+type legacyLogger struct {
+	logger Logger
+}
+
+func (l legacyLogger) Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField) {
+	switch event {
+	case LogReconnectFailed:
+		attempt, _ := logFieldValue(fields, "attempt").(uint)
+		err, _ := logFieldValue(fields, "error").(error)
+		l.logger.Report(event, conn, attempt, err)
+	case LogLastReconnectFailed:
+		err, _ := logFieldValue(fields, "error").(error)
+		l.logger.Report(event, conn, err)
+	case LogUnexpectedResultId:
+		resp, _ := logFieldValue(fields, "response").(*Response)
+		l.logger.Report(event, conn, resp)
+	}
+}
+
+func logFieldValue(fields []LogField, key string) interface{} {
+	for _, field := range fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// log passes event to logger of connection.
+func (conn *Connection) log(level LogLevel, event ConnLogKind, msg string, fields ...LogField) {
+	conn.opts.StructuredLogger.Log(level, event, conn, msg, fields...)
+}
+
+// logRecord is a record deferred by deferLog.
+type logRecord struct {
+	level  LogLevel
+	event  ConnLogKind
+	msg    string
+	fields []LogField
+}
+
+// deferLog saves record to pass it to logger by flushLogs, when locks of
+// connection are released. Logger could call methods of connection, so it
+// should not be called under locks.
+func (conn *Connection) deferLog(level LogLevel, event ConnLogKind, msg string, fields ...LogField) {
+	conn.logsMutex.Lock()
+	conn.logs = append(conn.logs, logRecord{level, event, msg, fields})
+	conn.logsMutex.Unlock()
+}
+
+// flushLogs passes deferred records to logger.
+func (conn *Connection) flushLogs() {
+	conn.logsMutex.Lock()
+	logs := conn.logs
+	conn.logs = nil
+	conn.logsMutex.Unlock()
+	for _, r := range logs {
+		conn.log(r.level, r.event, r.msg, r.fields...)
+	}
+}
+
+// unlock releases mutex of connection and flushes deferred records.
+func (conn *Connection) unlock() {
+	conn.mutex.Unlock()
+	conn.flushLogs()
+}
+
+// rateLimitedLogInterval is a minimal interval between LogRateLimited records.
+const rateLimitedLogInterval = time.Second
+
+// logRateLimited logs rate limited requests at most once per
+// rateLimitedLogInterval.
+func (conn *Connection) logRateLimited(requestCode int32) {
+	atomic.StoreInt32(&conn.rateLimitedCode, requestCode)
+	atomic.AddUint64(&conn.rateLimited, 1)
+	conn.flushRateLimited(false)
+}
+
+// flushRateLimited logs requests rate limited since previous record, if
+// rateLimitedLogInterval is passed or force is set. It is also called by
+// pinger and on close, so requests limited at the end of burst are logged.
+func (conn *Connection) flushRateLimited(force bool) {
+	if atomic.LoadUint64(&conn.rateLimited) == 0 {
+		return
+	}
+	now := int64(time.Now().Sub(epoch))
+	last := atomic.LoadInt64(&conn.rateLimitedLogged)
+	if !force && last != 0 && now-last < int64(rateLimitedLogInterval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&conn.rateLimitedLogged, last, now) {
+		return
+	}
+	count := atomic.SwapUint64(&conn.rateLimited, 0)
+	if count == 0 {
+		return
+	}
+	conn.log(LogLevelWarn, LogRateLimited, "requests are rate limited",
+		LogField{"request_code", atomic.LoadInt32(&conn.rateLimitedCode)},
+		LogField{"count", count})
+}
@@ -0,0 +1,36 @@
+//go:build go1.21
+// +build go1.21
+
+package tarantool
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger returns StructuredLogger writing records to logger of log/slog
+// package. Records have "event" and "addr" attributes in addition to fields
+// of event.
+func SlogLogger(logger *slog.Logger) StructuredLogger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(fields)+2)
+	attrs = append(attrs, slog.String("event", event.String()))
+	if conn != nil {
+		attrs = append(attrs, slog.String("addr", conn.Addr()))
+	}
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	l.logger.LogAttrs(ctx, slog.Level(level), msg, attrs...)
+}
@@ -0,0 +1,35 @@
+//go:build go1.21
+// +build go1.21
+
+package tarantool_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := SlogLogger(slog.New(handler))
+
+	logger.Log(LogLevelDebug, LogReconnecting, nil, "reconnecting")
+	logger.Log(LogLevelWarn, LogReconnectFailed, nil, "reconnect failed",
+		LogField{"attempt", uint(3)}, LogField{"error", errors.New("refused")})
+
+	out := buf.String()
+	if strings.Contains(out, "reconnecting") {
+		t.Errorf("Debug record is written with info level:\n%s", out)
+	}
+	for _, s := range []string{"level=WARN", `msg="reconnect failed"`,
+		"event=reconnect_failed", "attempt=3", "error=refused"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Record does not contain %q:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package tarantool_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+type reportedEvent struct {
+	event ConnLogKind
+	args  []interface{}
+}
+
+type recordingLogger struct {
+	events []reportedEvent
+}
+
+func (l *recordingLogger) Report(event ConnLogKind, conn *Connection, v ...interface{}) {
+	l.events = append(l.events, reportedEvent{event, v})
+}
+
+func TestAdaptLogger(t *testing.T) {
+	legacy := &recordingLogger{}
+	logger := AdaptLogger(legacy)
+	err := errors.New("connection refused")
+	resp := &Response{RequestId: 5}
+
+	logger.Log(LogLevelWarn, LogReconnectFailed, nil, "reconnect failed",
+		LogField{"attempt", uint(2)}, LogField{"error", err})
+	logger.Log(LogLevelError, LogLastReconnectFailed, nil, "last reconnect failed",
+		LogField{"error", err})
+	logger.Log(LogLevelWarn, LogUnexpectedResultId, nil, "unexpected response id",
+		LogField{"response", resp})
+	logger.Log(LogLevelInfo, LogConnected, nil, "connected")
+	logger.Log(LogLevelInfo, LogSchemaLoaded, nil, "schema loaded")
+
+	if len(legacy.events) != 3 {
+		t.Fatalf("Unexpected number of reported events: %v", legacy.events)
+	}
+	if e := legacy.events[0]; e.event != LogReconnectFailed ||
+		len(e.args) != 2 || e.args[0] != uint(2) || e.args[1] != err {
+		t.Errorf("Unexpected LogReconnectFailed arguments: %v", e.args)
+	}
+	if e := legacy.events[1]; e.event != LogLastReconnectFailed ||
+		len(e.args) != 1 || e.args[0] != err {
+		t.Errorf("Unexpected LogLastReconnectFailed arguments: %v", e.args)
+	}
+	if e := legacy.events[2]; e.event != LogUnexpectedResultId ||
+		len(e.args) != 1 || e.args[0] != resp {
+		t.Errorf("Unexpected LogUnexpectedResultId arguments: %v", e.args)
+	}
+}
+
+func TestLogNames(t *testing.T) {
+	if s := LogSchemaLoaded.String(); s != "schema_loaded" {
+		t.Errorf("Unexpected event name %q", s)
+	}
+	if s := LogLevelWarn.String(); s != "WARN" {
+		t.Errorf("Unexpected level name %q", s)
+	}
+}
+
+// reentrantLogger calls methods of connection, which take its locks.
+type reentrantLogger struct {
+	closed chan struct{}
+}
+
+func (l reentrantLogger) Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField) {
+	conn.RemoteAddr()
+	conn.Stats()
+	if event == LogClosed {
+		close(l.closed)
+	}
+}
+
+func TestLoggerCallsConnection(t *testing.T) {
+	logger := reentrantLogger{closed: make(chan struct{})}
+	conn, err := Connect("127.0.0.1:1", Opts{
+		Reconnect:        10 * time.Millisecond,
+		MaxReconnects:    2,
+		SkipSchema:       true,
+		StructuredLogger: logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create connection: %s", err)
+	}
+	defer conn.Close()
+	select {
+	case <-logger.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Connection is not closed, logger is deadlocked")
+	}
+}
+
+type countingLogger struct {
+	mutex  sync.Mutex
+	counts []interface{}
+}
+
+func (l *countingLogger) Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField) {
+	if event != LogRateLimited {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, f := range fields {
+		if f.Key == "count" {
+			l.counts = append(l.counts, f.Value)
+		}
+	}
+}
+
+func TestLogRateLimitedAggregated(t *testing.T) {
+	logger := &countingLogger{}
+	conn, err := Connect("127.0.0.1:1", Opts{
+		Reconnect:        time.Hour,
+		SkipSchema:       true,
+		StructuredLogger: logger,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create connection: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		conn.LogRateLimited(EvalRequest)
+	}
+	logger.mutex.Lock()
+	if len(logger.counts) != 1 || logger.counts[0] != uint64(1) {
+		t.Errorf("Rate limited requests are not aggregated: %v", logger.counts)
+	}
+	logger.mutex.Unlock()
+
+	// requests limited at the end of burst are logged on close
+	conn.Close()
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if len(logger.counts) != 2 || logger.counts[1] != uint64(99) {
+		t.Errorf("Rate limited requests are not logged on close: %v", logger.counts)
+	}
+}
@@ -0,0 +1,105 @@
+package multi
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// Node is a connected instance, which could receive request.
+type Node struct {
+	Addr string
+	Conn *tarantool.Connection
+	// RTT is a round trip time of last ping made by pool check. It is zero
+	// if it is not measured yet.
+	RTT time.Duration
+}
+
+// Balancer chooses instance to send request to among suitable ones. It is
+// specified in OptsMulti.Balancer and should be safe for concurrent use.
+type Balancer interface {
+	// Choose returns index of chosen node. Nodes are not empty and ordered
+	// as addresses of pool. Index out of range is treated as 0.
+	Choose(nodes []Node) int
+}
+
+// FirstBalancer chooses first node, so requests are sent to first connected
+// address, while others are standby. It is used if OptsMulti.Balancer is
+// not specified.
+type FirstBalancer struct{}
+
+// Choose returns 0.
+func (FirstBalancer) Choose(nodes []Node) int {
+	return 0
+}
+
+// RoundRobinBalancer chooses nodes in turn. Zero value is ready to use, it
+// should not be copied after first use.
+type RoundRobinBalancer struct {
+	next uint32
+}
+
+// Choose returns next node in turn.
+func (b *RoundRobinBalancer) Choose(nodes []Node) int {
+	return int((atomic.AddUint32(&b.next, 1) - 1) % uint32(len(nodes)))
+}
+
+// RandomBalancer chooses node at random.
+type RandomBalancer struct{}
+
+// Choose returns random node.
+func (RandomBalancer) Choose(nodes []Node) int {
+	return rand.Intn(len(nodes))
+}
+
+// LeastInFlightBalancer chooses node with least number of requests waiting
+// for response.
+type LeastInFlightBalancer struct{}
+
+// Choose returns first node with least tarantool.Connection.InFlight.
+func (LeastInFlightBalancer) Choose(nodes []Node) int {
+	best, min := 0, nodes[0].Conn.InFlight()
+	for i := 1; i < len(nodes); i++ {
+		if n := nodes[i].Conn.InFlight(); n < min {
+			best, min = i, n
+		}
+	}
+	return best
+}
+
+// LatencyBalancer chooses node at random with probability inversely
+// proportional to RTT, so faster nodes receive more requests. Nodes with
+// unknown RTT are treated as the fastest one.
+type LatencyBalancer struct{}
+
+// Choose returns random node weighted by 1/RTT.
+func (LatencyBalancer) Choose(nodes []Node) int {
+	var fastest time.Duration
+	for _, node := range nodes {
+		if node.RTT > 0 && (fastest == 0 || node.RTT < fastest) {
+			fastest = node.RTT
+		}
+	}
+	if fastest == 0 {
+		return rand.Intn(len(nodes))
+	}
+	weight := func(node Node) float64 {
+		if node.RTT <= 0 {
+			return 1 / float64(fastest)
+		}
+		return 1 / float64(node.RTT)
+	}
+	var sum float64
+	for _, node := range nodes {
+		sum += weight(node)
+	}
+	r := rand.Float64() * sum
+	for i, node := range nodes {
+		if r -= weight(node); r < 0 {
+			return i
+		}
+	}
+	return len(nodes) - 1
+}
@@ -13,6 +13,16 @@ rawset(_G, 'get_cluster_nodes', get_cluster_nodes)
 box.once("init", function()
     box.schema.user.create('test', { password = 'test' })
     box.schema.user.grant('test', 'read,write,execute', 'universe')
+
+    local s = box.schema.space.create('test', {
+        id = 517,
+        if_not_exists = true,
+    })
+    s:create_index('primary', {
+        type = 'tree',
+        parts = {1, 'uint'},
+        if_not_exists = true
+    })
 end)
 
 -- Set listen only when every other thing is configured.
@@ -0,0 +1,215 @@
+package multi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+var ErrNoInstances = errors.New("no instances are discovered")
+
+// Instance is an instance of cluster found by Discoverer.
+type Instance struct {
+	Addr       string
+	Replicaset string
+	// Role is a role of instance in cluster configuration. Pool uses it
+	// until actual role is detected by check or if check fails.
+	Role Role
+}
+
+// Discoverer reads topology of cluster through connection to any of its
+// instances. It is specified in OptsMulti.Discoverer.
+type Discoverer interface {
+	Discover(conn tarantool.Connector) ([]Instance, error)
+}
+
+// functionDiscoverer calls function, which returns list of addresses
+// (see OptsMulti.NodesGetFunctionName).
+type functionDiscoverer struct {
+	function string
+}
+
+func (d functionDiscoverer) Discover(conn tarantool.Connector) ([]Instance, error) {
+	var resp [][]string
+	if err := conn.Call17Typed(d.function, []interface{}{}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, nil
+	}
+	instances := make([]Instance, 0, len(resp[0]))
+	for _, addr := range resp[0] {
+		instances = append(instances, Instance{Addr: addr})
+	}
+	return instances, nil
+}
+
+const cartridgeDiscoveryExpr = `
+local res = {}
+for _, s in ipairs(require('cartridge').admin_get_servers()) do
+    local rs = s.replicaset
+    if rs ~= nil and not s.disabled then
+        local master = rs.active_master or rs.master
+        local role = 'replica'
+        if master ~= nil and master.uuid == s.uuid then
+            role = 'master'
+        end
+        table.insert(res, {s.uri, rs.uuid, rs.alias or '', role})
+    end
+end
+return res
+`
+
+// CartridgeDiscoverer reads topology of Cartridge cluster. Disabled and
+// unconfigured instances are skipped. Instance should allow Eval.
+type CartridgeDiscoverer struct {
+	// Replicaset is an UUID or alias of replicaset to discover. All
+	// replicasets are discovered if it is empty.
+	Replicaset string
+}
+
+func (d CartridgeDiscoverer) Discover(conn tarantool.Connector) ([]Instance, error) {
+	var resp [][][]string
+	if err := conn.EvalTyped(cartridgeDiscoveryExpr, []interface{}{}, &resp); err != nil {
+		return nil, err
+	}
+	return parseInstances(resp, d.Replicaset)
+}
+
+const configDiscoveryExpr = `
+local config = require('config')
+local res = {}
+for name, info in pairs(config:instances()) do
+    local opts = {instance = name}
+    local uri = config:get('iproto.advertise.client', opts)
+    if uri == nil then
+        local listen = config:get('iproto.listen', opts)
+        if listen ~= nil and listen[1] ~= nil then
+            uri = listen[1].uri
+        end
+    end
+    if uri ~= nil then
+        local mode = config:get('database.mode', opts)
+        local role = ''
+        if mode == 'rw' then
+            role = 'master'
+        elseif mode == 'ro' then
+            role = 'replica'
+        end
+        table.insert(res, {uri, info.replicaset_name or '', info.group_name or '', role})
+    end
+end
+return res
+`
+
+// ConfigDiscoverer reads topology of cluster configured with Tarantool 3
+// config module. Client address of instance is iproto.advertise.client or
+// first of iproto.listen.
+type ConfigDiscoverer struct {
+	// Replicaset is a name of replicaset to discover. All replicasets are
+	// discovered if it is empty.
+	Replicaset string
+}
+
+func (d ConfigDiscoverer) Discover(conn tarantool.Connector) ([]Instance, error) {
+	var resp [][][]string
+	if err := conn.EvalTyped(configDiscoveryExpr, []interface{}{}, &resp); err != nil {
+		return nil, err
+	}
+	return parseInstances(resp, d.Replicaset)
+}
+
+// parseInstances converts result of discovery expression: list of
+// {uri, replicaset, replicaset alias or group, role}.
+func parseInstances(resp [][][]string, replicaset string) ([]Instance, error) {
+	if len(resp) == 0 {
+		return nil, nil
+	}
+	instances := make([]Instance, 0, len(resp[0]))
+	for _, row := range resp[0] {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("unexpected instance description: %v", row)
+		}
+		if replicaset != "" && row[1] != replicaset && row[2] != replicaset {
+			continue
+		}
+		instance := Instance{Addr: row[0], Replicaset: row[1]}
+		switch row[3] {
+		case "master":
+			instance.Role = MasterRole
+		case "replica":
+			instance.Role = ReplicaRole
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// setConfigured stores roles of discovered instances.
+func (connMulti *ConnectionMulti) setConfigured(instances []Instance) {
+	configured := make(map[string]Role, len(instances))
+	for _, instance := range instances {
+		configured[instance.Addr] = instance.Role
+	}
+	connMulti.mutex.Lock()
+	connMulti.configured = configured
+	connMulti.mutex.Unlock()
+}
+
+// Discover reads topology of cluster through first available seed.
+func Discover(seeds []string, connOpts tarantool.Opts, discoverer Discoverer) ([]Instance, error) {
+	if len(seeds) == 0 {
+		return nil, ErrEmptyAddrs
+	}
+	// seed is not reconnected and its events are not needed
+	connOpts.Reconnect = 0
+	connOpts.Backoff = nil
+	connOpts.Notify = nil
+	connOpts.OnEvent = nil
+	var err error
+	for _, seed := range seeds {
+		var conn *tarantool.Connection
+		if conn, err = tarantool.Connect(seed, connOpts); err != nil {
+			continue
+		}
+		var instances []Instance
+		instances, err = discoverer.Discover(conn)
+		conn.Close()
+		if err == nil {
+			if len(instances) == 0 {
+				return nil, ErrNoInstances
+			}
+			return instances, nil
+		}
+	}
+	return nil, err
+}
+
+// discover updates addresses of pool with Discoverer.
+func (connMulti *ConnectionMulti) discover() {
+	instances, err := connMulti.opts.Discoverer.Discover(connMulti)
+	if err != nil || len(instances) == 0 {
+		return
+	}
+	connMulti.setConfigured(instances)
+	addrs := make([]string, len(instances))
+	for i, instance := range instances {
+		addrs[i] = instance.Addr
+	}
+	// Fill pool with new connections
+	for _, addr := range addrs {
+		if indexOf(addr, connMulti.getAddrs()) < 0 {
+			connMulti.add(addr, connMulti.connOpts)
+		}
+	}
+	// Clear pool from obsolete connections, they are closed in background
+	// so checks are not blocked by requests in flight
+	for _, addr := range connMulti.getAddrs() {
+		if indexOf(addr, addrs) < 0 {
+			if conn, _ := connMulti.remove(addr); conn != nil {
+				go closeGracefully(conn)
+			}
+		}
+	}
+}
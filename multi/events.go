@@ -0,0 +1,158 @@
+package multi
+
+import (
+	"sync"
+	"time"
+)
+
+// PoolEventKind is a kind of pool event.
+type PoolEventKind int
+
+const (
+	// NodeAdded signals that instance is added to pool.
+	NodeAdded PoolEventKind = iota + 1
+	// NodeRemoved signals that instance is removed from pool.
+	NodeRemoved
+	// NodeUp signals that instance is connected and passed check.
+	NodeUp
+	// NodeDown signals that instance is disconnected or failed check.
+	NodeDown
+	// ActiveSwitched signals that first connected instance is changed. It
+	// is sent only with FirstBalancer, which sends requests to it.
+	// Addr is empty if there are no connected instances.
+	ActiveSwitched
+	// PoolClosed signals that pool is closed. It is the last event.
+	PoolClosed
+)
+
+// PoolEvent is passed to handlers subscribed with OnEvent.
+type PoolEvent struct {
+	Kind PoolEventKind
+	Addr string
+	When time.Time
+}
+
+// eventQueue delivers events to subscriber in order from separate goroutine.
+type eventQueue struct {
+	handler func(PoolEvent)
+	mutex   sync.Mutex
+	events  []PoolEvent
+	wake    chan struct{}
+	stop    chan struct{}
+}
+
+func newEventQueue(handler func(PoolEvent)) *eventQueue {
+	q := &eventQueue{
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *eventQueue) push(event PoolEvent) {
+	q.mutex.Lock()
+	q.events = append(q.events, event)
+	q.mutex.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *eventQueue) pop() (event PoolEvent, ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.events) == 0 {
+		return
+	}
+	event = q.events[0]
+	q.events[0] = PoolEvent{}
+	q.events = q.events[1:]
+	return event, true
+}
+
+func (q *eventQueue) run() {
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		}
+		for {
+			event, ok := q.pop()
+			if !ok {
+				break
+			}
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+			q.handler(event)
+			if event.Kind == PoolClosed {
+				// there are no events after PoolClosed
+				return
+			}
+		}
+	}
+}
+
+// OnEvent subscribes handler to events of pool and returns function, which
+// unsubscribes it.
+//
+// Events are queued and passed to handler in order they happened from
+// separate goroutine, so slow handler does not block pool. PoolClosed event
+// is the last one. Use OptsMulti.OnEvent to receive events happened during
+// ConnectWithOpts.
+func (connMulti *ConnectionMulti) OnEvent(handler func(PoolEvent)) (unsubscribe func()) {
+	q := connMulti.subscribe(handler)
+	if q == nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { connMulti.unsubscribe(q) })
+	}
+}
+
+func (connMulti *ConnectionMulti) subscribe(handler func(PoolEvent)) *eventQueue {
+	connMulti.subMutex.Lock()
+	defer connMulti.subMutex.Unlock()
+	if connMulti.eventsDone {
+		return nil
+	}
+	q := newEventQueue(handler)
+	connMulti.subscribers = append(connMulti.subscribers, q)
+	return q
+}
+
+func (connMulti *ConnectionMulti) unsubscribe(q *eventQueue) {
+	connMulti.subMutex.Lock()
+	defer connMulti.subMutex.Unlock()
+	for i, s := range connMulti.subscribers {
+		if s == q {
+			connMulti.subscribers = append(connMulti.subscribers[:i], connMulti.subscribers[i+1:]...)
+			break
+		}
+	}
+	close(q.stop)
+}
+
+// sendEvent passes event to subscribers.
+func (connMulti *ConnectionMulti) sendEvent(kind PoolEventKind, addr string) {
+	event := PoolEvent{Kind: kind, Addr: addr, When: time.Now()}
+	connMulti.subMutex.Lock()
+	defer connMulti.subMutex.Unlock()
+	if connMulti.eventsDone {
+		return
+	}
+	for _, q := range connMulti.subscribers {
+		q.push(event)
+	}
+	if kind == PoolClosed {
+		connMulti.eventsDone = true
+		connMulti.subscribers = nil
+	}
+}
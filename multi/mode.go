@@ -0,0 +1,381 @@
+package multi
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// Mode defines instances, which could receive request.
+type Mode int
+
+const (
+	// ANY allows any connected instance.
+	ANY Mode = iota
+	// RW allows only writable instances (masters).
+	RW
+	// RO allows only read-only instances (replicas).
+	RO
+	// PreferRW allows any instance, but writable ones are preferred.
+	PreferRW
+	// PreferRO allows any instance, but read-only ones are preferred.
+	PreferRO
+)
+
+// writable is a mode of data modification requests sent through views
+// other than RW. Writable instances are preferred, but instances with
+// unknown role are used if there are none: role check could fail (e.g. on
+// lack of execute access) and it is reset on reconnect, while write sent to
+// read-only instance is redirected anyway.
+const writable Mode = -1
+
+// Role is a role of instance detected by box.info.ro.
+type Role int
+
+const (
+	// UnknownRole is a role of instance, which is not checked yet or check
+	// failed.
+	UnknownRole Role = iota
+	// MasterRole is a role of writable instance.
+	MasterRole
+	// ReplicaRole is a role of read-only instance.
+	ReplicaRole
+)
+
+var (
+	ErrNoRwConnection = errors.New("no connection to writable instance")
+	ErrNoRoConnection = errors.New("no connection to read-only instance")
+)
+
+// ModeConnection is a view of pool, which sends requests to instances
+// chosen by mode. Data modification requests (Insert, Replace, Delete,
+// Update and Upsert) are sent to writable instances, or to instances with
+// unknown role if there are none and mode is not RW.
+type ModeConnection struct {
+	pool      *ConnectionMulti
+	readMode  Mode
+	callMode  Mode
+	writeMode Mode
+}
+
+var _ = tarantool.Connector(&ModeConnection{}) // check compatibility with connector interface
+
+// WithMode returns view of pool, which sends Select, Call, Call17 and Eval
+// requests to instances chosen by mode.
+func (connMulti *ConnectionMulti) WithMode(mode Mode) *ModeConnection {
+	return newModeConnection(connMulti, mode)
+}
+
+func newModeConnection(connMulti *ConnectionMulti, mode Mode) *ModeConnection {
+	writeMode := writable
+	if mode == RW {
+		writeMode = RW
+	}
+	return &ModeConnection{pool: connMulti, readMode: mode, callMode: mode, writeMode: writeMode}
+}
+
+// Role returns role of instance detected by last check. It is UnknownRole
+// for addresses out of pool.
+func (connMulti *ConnectionMulti) Role(addr string) Role {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+	return connMulti.nodes[addr].role
+}
+
+// getConnection returns connection to instance matching mode chosen by
+// balancer. If there are no connected instances, disconnected one is
+// returned, so request fails with tarantool.ErrConnectionNotReady.
+func (connMulti *ConnectionMulti) getConnection(mode Mode) (*tarantool.Connection, error) {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+
+	fallback := connMulti.fallback
+	var masters, replicas, unknown, connected []Node
+	for _, addr := range connMulti.addrs {
+		conn := connMulti.pool[addr]
+		if conn == nil {
+			continue
+		}
+		if !conn.ConnectedNow() {
+			fallback = conn
+			continue
+		}
+		info := connMulti.nodes[addr]
+		node := Node{Addr: addr, Conn: conn, RTT: info.rtt}
+		connected = append(connected, node)
+		switch info.role {
+		case MasterRole:
+			masters = append(masters, node)
+		case ReplicaRole:
+			replicas = append(replicas, node)
+		default:
+			unknown = append(unknown, node)
+		}
+	}
+	if len(connected) == 0 {
+		if fallback == nil {
+			return nil, ErrNoConnection
+		}
+		return fallback, nil
+	}
+	// instance with unknown role could be read-only, so it is used only
+	// when any instance is allowed
+	nodes := connected
+	switch mode {
+	case RW:
+		if nodes = masters; len(nodes) == 0 {
+			return nil, ErrNoRwConnection
+		}
+	case writable:
+		if nodes = firstNonEmpty(masters, unknown); len(nodes) == 0 {
+			return nil, ErrNoRwConnection
+		}
+	case RO:
+		if nodes = replicas; len(nodes) == 0 {
+			return nil, ErrNoRoConnection
+		}
+	case PreferRW:
+		nodes = firstNonEmpty(masters, unknown, replicas)
+	case PreferRO:
+		nodes = firstNonEmpty(replicas, unknown, masters)
+	}
+	i := connMulti.balancer.Choose(nodes)
+	if i < 0 || i >= len(nodes) {
+		// misbehaving custom balancer should not crash requests
+		i = 0
+	}
+	return nodes[i].Conn, nil
+}
+
+func firstNonEmpty(groups ...[]Node) []Node {
+	for _, nodes := range groups {
+		if len(nodes) > 0 {
+			return nodes
+		}
+	}
+	return nil
+}
+
+func (c *ModeConnection) ConnectedNow() bool {
+	if c.pool.getState() != connConnected {
+		return false
+	}
+	conn, err := c.pool.getConnection(c.readMode)
+	return err == nil && conn.ConnectedNow()
+}
+
+// Close closes pool.
+func (c *ModeConnection) Close() error {
+	return c.pool.Close()
+}
+
+func (c *ModeConnection) ConfiguredTimeout() time.Duration {
+	return c.pool.connOpts.Timeout
+}
+
+func (c *ModeConnection) Ping() (resp *tarantool.Response, err error) {
+	conn, err := c.pool.getConnection(c.readMode)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Ping()
+}
+
+func (c *ModeConnection) Select(space, index interface{}, offset, limit, iterator uint32, key interface{}) (resp *tarantool.Response, err error) {
+	conn, err := c.pool.getConnection(c.readMode)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Select(space, index, offset, limit, iterator, key)
+}
+
+func (c *ModeConnection) Insert(space interface{}, tuple interface{}) (resp *tarantool.Response, err error) {
+	err = c.write(func(conn *tarantool.Connection) error {
+		resp, err = conn.Insert(space, tuple)
+		return err
+	})
+	return
+}
+
+func (c *ModeConnection) Replace(space interface{}, tuple interface{}) (resp *tarantool.Response, err error) {
+	err = c.write(func(conn *tarantool.Connection) error {
+		resp, err = conn.Replace(space, tuple)
+		return err
+	})
+	return
+}
+
+func (c *ModeConnection) Delete(space, index interface{}, key interface{}) (resp *tarantool.Response, err error) {
+	err = c.write(func(conn *tarantool.Connection) error {
+		resp, err = conn.Delete(space, index, key)
+		return err
+	})
+	return
+}
+
+func (c *ModeConnection) Update(space, index interface{}, key, ops interface{}) (resp *tarantool.Response, err error) {
+	err = c.write(func(conn *tarantool.Connection) error {
+		resp, err = conn.Update(space, index, key, ops)
+		return err
+	})
+	return
+}
+
+func (c *ModeConnection) Upsert(space interface{}, tuple, ops interface{}) (resp *tarantool.Response, err error) {
+	err = c.write(func(conn *tarantool.Connection) error {
+		resp, err = conn.Upsert(space, tuple, ops)
+		return err
+	})
+	return
+}
+
+func (c *ModeConnection) Call(functionName string, args interface{}) (resp *tarantool.Response, err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Call(functionName, args)
+}
+
+func (c *ModeConnection) Call17(functionName string, args interface{}) (resp *tarantool.Response, err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Call17(functionName, args)
+}
+
+func (c *ModeConnection) Eval(expr string, args interface{}) (resp *tarantool.Response, err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Eval(expr, args)
+}
+
+func (c *ModeConnection) GetTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
+	conn, err := c.pool.getConnection(c.readMode)
+	if err != nil {
+		return err
+	}
+	return conn.GetTyped(space, index, key, result)
+}
+
+func (c *ModeConnection) SelectTyped(space, index interface{}, offset, limit, iterator uint32, key interface{}, result interface{}) (err error) {
+	conn, err := c.pool.getConnection(c.readMode)
+	if err != nil {
+		return err
+	}
+	return conn.SelectTyped(space, index, offset, limit, iterator, key, result)
+}
+
+func (c *ModeConnection) InsertTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
+	return c.write(func(conn *tarantool.Connection) error {
+		return conn.InsertTyped(space, tuple, result)
+	})
+}
+
+func (c *ModeConnection) ReplaceTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
+	return c.write(func(conn *tarantool.Connection) error {
+		return conn.ReplaceTyped(space, tuple, result)
+	})
+}
+
+func (c *ModeConnection) DeleteTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
+	return c.write(func(conn *tarantool.Connection) error {
+		return conn.DeleteTyped(space, index, key, result)
+	})
+}
+
+func (c *ModeConnection) UpdateTyped(space, index interface{}, key, ops interface{}, result interface{}) (err error) {
+	return c.write(func(conn *tarantool.Connection) error {
+		return conn.UpdateTyped(space, index, key, ops, result)
+	})
+}
+
+func (c *ModeConnection) CallTyped(functionName string, args interface{}, result interface{}) (err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return err
+	}
+	return conn.CallTyped(functionName, args, result)
+}
+
+func (c *ModeConnection) Call17Typed(functionName string, args interface{}, result interface{}) (err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return err
+	}
+	return conn.Call17Typed(functionName, args, result)
+}
+
+func (c *ModeConnection) EvalTyped(expr string, args interface{}, result interface{}) (err error) {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return err
+	}
+	return conn.EvalTyped(expr, args, result)
+}
+
+func (c *ModeConnection) SelectAsync(space, index interface{}, offset, limit, iterator uint32, key interface{}) *tarantool.Future {
+	conn, err := c.pool.getConnection(c.readMode)
+	if err != nil {
+		return tarantool.NewErrorFuture(err)
+	}
+	return conn.SelectAsync(space, index, offset, limit, iterator, key)
+}
+
+func (c *ModeConnection) InsertAsync(space interface{}, tuple interface{}) *tarantool.Future {
+	return c.writeAsync(func(conn *tarantool.Connection) *tarantool.Future {
+		return conn.InsertAsync(space, tuple)
+	})
+}
+
+func (c *ModeConnection) ReplaceAsync(space interface{}, tuple interface{}) *tarantool.Future {
+	return c.writeAsync(func(conn *tarantool.Connection) *tarantool.Future {
+		return conn.ReplaceAsync(space, tuple)
+	})
+}
+
+func (c *ModeConnection) DeleteAsync(space, index interface{}, key interface{}) *tarantool.Future {
+	return c.writeAsync(func(conn *tarantool.Connection) *tarantool.Future {
+		return conn.DeleteAsync(space, index, key)
+	})
+}
+
+func (c *ModeConnection) UpdateAsync(space, index interface{}, key, ops interface{}) *tarantool.Future {
+	return c.writeAsync(func(conn *tarantool.Connection) *tarantool.Future {
+		return conn.UpdateAsync(space, index, key, ops)
+	})
+}
+
+func (c *ModeConnection) UpsertAsync(space interface{}, tuple interface{}, ops interface{}) *tarantool.Future {
+	return c.writeAsync(func(conn *tarantool.Connection) *tarantool.Future {
+		return conn.UpsertAsync(space, tuple, ops)
+	})
+}
+
+func (c *ModeConnection) CallAsync(functionName string, args interface{}) *tarantool.Future {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return tarantool.NewErrorFuture(err)
+	}
+	return conn.CallAsync(functionName, args)
+}
+
+func (c *ModeConnection) Call17Async(functionName string, args interface{}) *tarantool.Future {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return tarantool.NewErrorFuture(err)
+	}
+	return conn.Call17Async(functionName, args)
+}
+
+func (c *ModeConnection) EvalAsync(expr string, args interface{}) *tarantool.Future {
+	conn, err := c.pool.getConnection(c.callMode)
+	if err != nil {
+		return tarantool.NewErrorFuture(err)
+	}
+	return conn.EvalAsync(expr, args)
+}
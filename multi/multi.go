@@ -18,8 +18,19 @@ var (
 	ErrEmptyAddrs        = errors.New("addrs should not be empty")
 	ErrWrongCheckTimeout = errors.New("wrong check timeout, must be greater than 0")
 	ErrNoConnection      = errors.New("no active connections")
+	ErrAddrExists        = errors.New("address is already in pool")
+	ErrAddrNotFound      = errors.New("address is not in pool")
+	ErrClosed            = errors.New("pool is closed")
 )
 
+// drainInterval is a period of check for completion of requests to
+// removed instance.
+const drainInterval = 10 * time.Millisecond
+
+// maxDrainTime limits waiting for completion of requests to removed
+// instance, if connection has no request timeout.
+const maxDrainTime = 30 * time.Second
+
 func indexOf(sstring string, data []string) int {
 	for i, v := range data {
 		if sstring == v {
@@ -39,7 +50,21 @@ type ConnectionMulti struct {
 	state    uint32
 	control  chan struct{}
 	pool     map[string]*tarantool.Connection
+	addrOpts map[string]tarantool.Opts
+	nodes    map[string]nodeInfo
+	// configured are roles of instances in cluster configuration read by
+	// Discoverer.
+	configured map[string]Role
+	// active is the first connected instance, it is tracked only with
+	// FirstBalancer.
+	active   string
+	balancer Balancer
 	fallback *tarantool.Connection
+	anyMode  ModeConnection
+	// subscribers receive events, see OnEvent.
+	subMutex    sync.Mutex
+	subscribers []*eventQueue
+	eventsDone  bool
 }
 
 var _ = tarantool.Connector(&ConnectionMulti{}) // check compatibility with connector interface
@@ -48,6 +73,22 @@ type OptsMulti struct {
 	CheckTimeout         time.Duration
 	NodesGetFunctionName string
 	ClusterDiscoveryTime time.Duration
+	// Balancer chooses instance among suitable ones for each request.
+	// FirstBalancer is used by default.
+	Balancer Balancer
+	// OnEvent receives all events of pool, including ones happened during
+	// ConnectWithOpts, see ConnectionMulti.OnEvent.
+	OnEvent func(PoolEvent)
+	// Discoverer reads topology of cluster. If it is specified, addresses
+	// passed to ConnectWithOpts are seeds, pool is bootstrapped with
+	// instances discovered through first available seed and addresses are
+	// updated every ClusterDiscoveryTime. It takes precedence over
+	// NodesGetFunctionName.
+	Discoverer Discoverer
+	// MaxRedirects is a maximum number of resends of data modification
+	// request rejected by instance, which turned out to be read-only. It is
+	// 2 by default, negative value disables redirects.
+	MaxRedirects int
 }
 
 func ConnectWithOpts(addrs []string, connOpts tarantool.Opts, opts OptsMulti) (connMulti *ConnectionMulti, err error) {
@@ -60,22 +101,47 @@ func ConnectWithOpts(addrs []string, connOpts tarantool.Opts, opts OptsMulti) (c
 	if opts.ClusterDiscoveryTime <= 0 {
 		opts.ClusterDiscoveryTime = 60 * time.Second
 	}
+	if opts.MaxRedirects == 0 {
+		opts.MaxRedirects = defaultMaxRedirects
+	}
+	var instances []Instance
+	if opts.Discoverer != nil {
+		if instances, err = Discover(addrs, connOpts, opts.Discoverer); err != nil {
+			return nil, err
+		}
+		addrs = make([]string, len(instances))
+		for i, instance := range instances {
+			addrs[i] = instance.Addr
+		}
+	} else if opts.NodesGetFunctionName != "" {
+		opts.Discoverer = functionDiscoverer{opts.NodesGetFunctionName}
+	}
 
-	notify := make(chan tarantool.ConnEvent, 10*len(addrs)) // x10 to accept disconnected and closed event (with a margin)
-	connOpts.Notify = notify
 	connMulti = &ConnectionMulti{
 		addrs:    addrs,
-		connOpts: connOpts,
 		opts:     opts,
-		notify:   notify,
+		notify:   make(chan tarantool.ConnEvent),
 		control:  make(chan struct{}),
 		pool:     make(map[string]*tarantool.Connection),
+		addrOpts: make(map[string]tarantool.Opts),
+		nodes:    make(map[string]nodeInfo),
+		balancer: opts.Balancer,
 	}
+	connMulti.setConfigured(instances)
+	if opts.OnEvent != nil {
+		connMulti.subscribe(opts.OnEvent)
+	}
+	if connMulti.balancer == nil {
+		connMulti.balancer = FirstBalancer{}
+	}
+	connMulti.connOpts = connMulti.watchClose(connOpts)
+	connMulti.anyMode = *newModeConnection(connMulti, ANY)
 	somebodyAlive, _ := connMulti.warmUp()
 	if !somebodyAlive {
 		connMulti.Close()
 		return nil, ErrNoConnection
 	}
+	connMulti.updateActive()
 	go connMulti.checker()
 
 	return connMulti, nil
@@ -88,6 +154,141 @@ func Connect(addrs []string, connOpts tarantool.Opts) (connMulti *ConnectionMult
 	return ConnectWithOpts(addrs, connOpts, opts)
 }
 
+// watchClose returns copy of opts, which OnEvent handler notifies pool
+// about closed connections. Events are delivered reliably to OnEvent
+// subscriber, so closing of connection is not missed.
+func (connMulti *ConnectionMulti) watchClose(opts tarantool.Opts) tarantool.Opts {
+	onEvent := opts.OnEvent
+	opts.OnEvent = func(event tarantool.ConnEvent) {
+		if onEvent != nil {
+			onEvent(event)
+		}
+		if event.Kind != tarantool.Closed {
+			return
+		}
+		select {
+		case connMulti.notify <- event:
+		case <-connMulti.control:
+		}
+	}
+	return opts
+}
+
+// connect makes new connection to instance in pool.
+func (connMulti *ConnectionMulti) connect(addr string) (*tarantool.Connection, error) {
+	connMulti.mutex.RLock()
+	opts, ok := connMulti.addrOpts[addr]
+	connMulti.mutex.RUnlock()
+	if !ok {
+		opts = connMulti.connOpts
+	}
+	return tarantool.Connect(addr, opts)
+}
+
+// getAddrs returns addresses of pool. Slice is replaced on change, so it
+// could be used without lock.
+func (connMulti *ConnectionMulti) getAddrs() []string {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+	return connMulti.addrs
+}
+
+// Add connects to instance with opts and adds it to pool. Address is not
+// added if connect fails.
+func (connMulti *ConnectionMulti) Add(addr string, opts tarantool.Opts) error {
+	return connMulti.add(addr, connMulti.watchClose(opts))
+}
+
+func (connMulti *ConnectionMulti) add(addr string, opts tarantool.Opts) error {
+	if connMulti.getState() == connClosed {
+		return ErrClosed
+	}
+	if indexOf(addr, connMulti.getAddrs()) >= 0 {
+		return ErrAddrExists
+	}
+	conn, err := tarantool.Connect(addr, opts)
+	if err != nil {
+		return err
+	}
+
+	connMulti.mutex.Lock()
+	if connMulti.getState() == connClosed {
+		err = ErrClosed
+	} else if indexOf(addr, connMulti.addrs) >= 0 {
+		err = ErrAddrExists
+	}
+	if err != nil {
+		connMulti.mutex.Unlock()
+		conn.Close()
+		return err
+	}
+	addrs := make([]string, len(connMulti.addrs), len(connMulti.addrs)+1)
+	copy(addrs, connMulti.addrs)
+	connMulti.addrs = append(addrs, addr)
+	connMulti.pool[addr] = conn
+	connMulti.addrOpts[addr] = opts
+	connMulti.mutex.Unlock()
+
+	connMulti.sendEvent(NodeAdded, addr)
+	connMulti.checkNode(addr, conn)
+	connMulti.updateActive()
+	return nil
+}
+
+// Remove removes instance from pool. New requests are not sent to it
+// anymore, connection is closed after requests in flight are completed or
+// timed out.
+func (connMulti *ConnectionMulti) Remove(addr string) error {
+	conn, err := connMulti.remove(addr)
+	if err != nil || conn == nil {
+		return err
+	}
+	return closeGracefully(conn)
+}
+
+// remove removes instance from pool and returns its connection, which
+// should be closed by caller.
+func (connMulti *ConnectionMulti) remove(addr string) (*tarantool.Connection, error) {
+	connMulti.mutex.Lock()
+	i := indexOf(addr, connMulti.addrs)
+	if i < 0 {
+		connMulti.mutex.Unlock()
+		return nil, ErrAddrNotFound
+	}
+	addrs := make([]string, 0, len(connMulti.addrs)-1)
+	addrs = append(addrs, connMulti.addrs[:i]...)
+	connMulti.addrs = append(addrs, connMulti.addrs[i+1:]...)
+	conn := connMulti.pool[addr]
+	delete(connMulti.pool, addr)
+	delete(connMulti.addrOpts, addr)
+	delete(connMulti.nodes, addr)
+	if connMulti.fallback == conn {
+		connMulti.fallback = nil
+	}
+	connMulti.mutex.Unlock()
+
+	connMulti.sendEvent(NodeRemoved, addr)
+	connMulti.updateActive()
+	return conn, nil
+}
+
+// closeGracefully closes connection after requests in flight are completed
+// or timed out, but waits at most maxDrainTime without request timeout.
+func closeGracefully(conn *tarantool.Connection) error {
+	timeout := conn.ConfiguredTimeout()
+	if timeout <= 0 {
+		timeout = maxDrainTime
+	}
+	deadline := time.Now().Add(timeout)
+	for conn.InFlight() > 0 && conn.ConnectedNow() && time.Now().Before(deadline) {
+		time.Sleep(drainInterval)
+	}
+	if conn.ClosedNow() {
+		return nil
+	}
+	return conn.Close()
+}
+
 func (connMulti *ConnectionMulti) warmUp() (somebodyAlive bool, errs []error) {
 	errs = make([]error, len(connMulti.addrs))
 
@@ -101,7 +302,10 @@ func (connMulti *ConnectionMulti) warmUp() (somebodyAlive bool, errs []error) {
 			connMulti.pool[addr] = conn
 			if conn.ConnectedNow() {
 				somebodyAlive = true
+				connMulti.checkNode(addr, conn)
 			}
+		} else {
+			connMulti.markDown(addr, err)
 		}
 	}
 	return
@@ -118,16 +322,24 @@ func (connMulti *ConnectionMulti) getConnectionFromPool(addr string) (*tarantool
 	return conn, ok
 }
 
+// setConnectionToPool replaces connection to instance. Connection is
+// closed if instance is removed from pool.
 func (connMulti *ConnectionMulti) setConnectionToPool(addr string, conn *tarantool.Connection) {
 	connMulti.mutex.Lock()
 	defer connMulti.mutex.Unlock()
+	if indexOf(addr, connMulti.addrs) < 0 {
+		conn.Close()
+		return
+	}
 	connMulti.pool[addr] = conn
+	connMulti.resetNode(addr)
 }
 
 func (connMulti *ConnectionMulti) deleteConnectionFromPool(addr string) {
 	connMulti.mutex.Lock()
 	defer connMulti.mutex.Unlock()
 	delete(connMulti.pool, addr)
+	connMulti.resetNode(addr)
 }
 
 func (connMulti *ConnectionMulti) checker() {
@@ -148,50 +360,26 @@ func (connMulti *ConnectionMulti) checker() {
 			}
 			if e.Conn.ClosedNow() {
 				addr := e.Conn.Addr()
-				if _, ok := connMulti.getConnectionFromPool(addr); !ok {
+				if conn, ok := connMulti.getConnectionFromPool(addr); !ok || conn != e.Conn {
 					continue
 				}
-				conn, _ := tarantool.Connect(addr, connMulti.connOpts)
+				conn, err := connMulti.connect(addr)
 				if conn != nil {
 					connMulti.setConnectionToPool(addr, conn)
+					connMulti.checkNode(addr, conn)
 				} else {
 					connMulti.deleteConnectionFromPool(addr)
+					connMulti.markDown(addr, err)
 				}
+				connMulti.updateActive()
 			}
 		case <-refreshTimer.C:
-			if connMulti.getState() == connClosed || connMulti.opts.NodesGetFunctionName == "" {
+			if connMulti.getState() == connClosed || connMulti.opts.Discoverer == nil {
 				continue
 			}
-			var resp [][]string
-			err := connMulti.Call17Typed(connMulti.opts.NodesGetFunctionName, []interface{}{}, &resp)
-			if err != nil {
-				continue
-			}
-			if len(resp) > 0 && len(resp[0]) > 0 {
-				addrs := resp[0]
-				// Fill pool with new connections
-				for _, v := range addrs {
-					if indexOf(v, connMulti.addrs) < 0 {
-						conn, _ := tarantool.Connect(v, connMulti.connOpts)
-						if conn != nil {
-							connMulti.setConnectionToPool(v, conn)
-						}
-					}
-				}
-				// Clear pool from obsolete connections
-				for _, v := range connMulti.addrs {
-					if indexOf(v, addrs) < 0 {
-						con, ok := connMulti.getConnectionFromPool(v)
-						if con != nil && ok {
-							con.Close()
-						}
-						connMulti.deleteConnectionFromPool(v)
-					}
-				}
-				connMulti.addrs = addrs
-			}
+			connMulti.discover()
 		case <-timer.C:
-			for _, addr := range connMulti.addrs {
+			for _, addr := range connMulti.getAddrs() {
 				if connMulti.getState() == connClosed {
 					return
 				}
@@ -200,33 +388,41 @@ func (connMulti *ConnectionMulti) checker() {
 						continue
 					}
 				}
-				conn, _ := tarantool.Connect(addr, connMulti.connOpts)
+				conn, err := connMulti.connect(addr)
 				if conn != nil {
 					connMulti.setConnectionToPool(addr, conn)
+				} else {
+					connMulti.markDown(addr, err)
 				}
 			}
+			connMulti.checkNodes()
+			connMulti.updateActive()
 		}
 	}
 }
 
 func (connMulti *ConnectionMulti) getCurrentConnection() *tarantool.Connection {
-	connMulti.mutex.RLock()
-	defer connMulti.mutex.RUnlock()
-
-	for _, addr := range connMulti.addrs {
-		conn := connMulti.pool[addr]
-		if conn != nil {
-			if conn.ConnectedNow() {
-				return conn
-			}
-			connMulti.fallback = conn
-		}
-	}
-	return connMulti.fallback
+	conn, _ := connMulti.getConnection(ANY)
+	return conn
 }
 
 func (connMulti *ConnectionMulti) ConnectedNow() bool {
-	return connMulti.getState() == connConnected && connMulti.getCurrentConnection().ConnectedNow()
+	return connMulti.anyMode.ConnectedNow()
+}
+
+// ClosedNow reports if pool is closed by user.
+func (connMulti *ConnectionMulti) ClosedNow() bool {
+	return connMulti.getState() == connClosed
+}
+
+// Handle returns user specified handle from connection Opts.
+func (connMulti *ConnectionMulti) Handle() interface{} {
+	return connMulti.connOpts.Handle
+}
+
+// Done returns channel, which is closed when pool is closed.
+func (connMulti *ConnectionMulti) Done() <-chan struct{} {
+	return connMulti.control
 }
 
 func (connMulti *ConnectionMulti) Close() (err error) {
@@ -234,7 +430,7 @@ func (connMulti *ConnectionMulti) Close() (err error) {
 	defer connMulti.mutex.Unlock()
 
 	close(connMulti.control)
-	connMulti.state = connClosed
+	atomic.StoreUint32(&connMulti.state, connClosed)
 
 	for _, conn := range connMulti.pool {
 		if err == nil {
@@ -246,122 +442,163 @@ func (connMulti *ConnectionMulti) Close() (err error) {
 	if connMulti.fallback != nil {
 		connMulti.fallback.Close()
 	}
+	connMulti.sendEvent(PoolClosed, "")
 
 	return
 }
 
+// Stats contains aggregated statistics of pool connections.
+type Stats struct {
+	// Stats is a sum of statistics of all connections in pool.
+	tarantool.Stats
+	// ByAddr contains statistics of each connection in pool.
+	ByAddr map[string]tarantool.Stats
+	// Connected reports if connection to address is established.
+	Connected map[string]bool
+}
+
+// Connections returns connections of pool by address of instance.
+func (connMulti *ConnectionMulti) Connections() map[string]*tarantool.Connection {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+	conns := make(map[string]*tarantool.Connection, len(connMulti.pool))
+	for addr, conn := range connMulti.pool {
+		conns[addr] = conn
+	}
+	return conns
+}
+
+// Stats returns statistics of connections in pool aggregated and by address.
+// Note: statistics of connection are lost when it is removed from pool.
+func (connMulti *ConnectionMulti) Stats() Stats {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+
+	stats := Stats{
+		ByAddr:    make(map[string]tarantool.Stats, len(connMulti.pool)),
+		Connected: make(map[string]bool, len(connMulti.pool)),
+	}
+	for addr, conn := range connMulti.pool {
+		connStats := conn.Stats()
+		stats.Connected[addr] = conn.ConnectedNow()
+		stats.ByAddr[addr] = connStats
+		stats.Add(connStats)
+	}
+	return stats
+}
+
 func (connMulti *ConnectionMulti) Ping() (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Ping()
+	return connMulti.anyMode.Ping()
 }
 
 func (connMulti *ConnectionMulti) ConfiguredTimeout() time.Duration {
-	return connMulti.getCurrentConnection().ConfiguredTimeout()
+	return connMulti.connOpts.Timeout
 }
 
 func (connMulti *ConnectionMulti) Select(space, index interface{}, offset, limit, iterator uint32, key interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Select(space, index, offset, limit, iterator, key)
+	return connMulti.anyMode.Select(space, index, offset, limit, iterator, key)
 }
 
 func (connMulti *ConnectionMulti) Insert(space interface{}, tuple interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Insert(space, tuple)
+	return connMulti.anyMode.Insert(space, tuple)
 }
 
 func (connMulti *ConnectionMulti) Replace(space interface{}, tuple interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Replace(space, tuple)
+	return connMulti.anyMode.Replace(space, tuple)
 }
 
 func (connMulti *ConnectionMulti) Delete(space, index interface{}, key interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Delete(space, index, key)
+	return connMulti.anyMode.Delete(space, index, key)
 }
 
 func (connMulti *ConnectionMulti) Update(space, index interface{}, key, ops interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Update(space, index, key, ops)
+	return connMulti.anyMode.Update(space, index, key, ops)
 }
 
 func (connMulti *ConnectionMulti) Upsert(space interface{}, tuple, ops interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Upsert(space, tuple, ops)
+	return connMulti.anyMode.Upsert(space, tuple, ops)
 }
 
 func (connMulti *ConnectionMulti) Call(functionName string, args interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Call(functionName, args)
+	return connMulti.anyMode.Call(functionName, args)
 }
 
 func (connMulti *ConnectionMulti) Call17(functionName string, args interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Call17(functionName, args)
+	return connMulti.anyMode.Call17(functionName, args)
 }
 
 func (connMulti *ConnectionMulti) Eval(expr string, args interface{}) (resp *tarantool.Response, err error) {
-	return connMulti.getCurrentConnection().Eval(expr, args)
+	return connMulti.anyMode.Eval(expr, args)
 }
 
 func (connMulti *ConnectionMulti) GetTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().GetTyped(space, index, key, result)
+	return connMulti.anyMode.GetTyped(space, index, key, result)
 }
 
 func (connMulti *ConnectionMulti) SelectTyped(space, index interface{}, offset, limit, iterator uint32, key interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().SelectTyped(space, index, offset, limit, iterator, key, result)
+	return connMulti.anyMode.SelectTyped(space, index, offset, limit, iterator, key, result)
 }
 
 func (connMulti *ConnectionMulti) InsertTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().InsertTyped(space, tuple, result)
+	return connMulti.anyMode.InsertTyped(space, tuple, result)
 }
 
 func (connMulti *ConnectionMulti) ReplaceTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().ReplaceTyped(space, tuple, result)
+	return connMulti.anyMode.ReplaceTyped(space, tuple, result)
 }
 
 func (connMulti *ConnectionMulti) DeleteTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().DeleteTyped(space, index, key, result)
+	return connMulti.anyMode.DeleteTyped(space, index, key, result)
 }
 
 func (connMulti *ConnectionMulti) UpdateTyped(space, index interface{}, key, ops interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().UpdateTyped(space, index, key, ops, result)
+	return connMulti.anyMode.UpdateTyped(space, index, key, ops, result)
 }
 
 func (connMulti *ConnectionMulti) CallTyped(functionName string, args interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().CallTyped(functionName, args, result)
+	return connMulti.anyMode.CallTyped(functionName, args, result)
 }
 
 func (connMulti *ConnectionMulti) Call17Typed(functionName string, args interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().Call17Typed(functionName, args, result)
+	return connMulti.anyMode.Call17Typed(functionName, args, result)
 }
 
 func (connMulti *ConnectionMulti) EvalTyped(expr string, args interface{}, result interface{}) (err error) {
-	return connMulti.getCurrentConnection().EvalTyped(expr, args, result)
+	return connMulti.anyMode.EvalTyped(expr, args, result)
 }
 
 func (connMulti *ConnectionMulti) SelectAsync(space, index interface{}, offset, limit, iterator uint32, key interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().SelectAsync(space, index, offset, limit, iterator, key)
+	return connMulti.anyMode.SelectAsync(space, index, offset, limit, iterator, key)
 }
 
 func (connMulti *ConnectionMulti) InsertAsync(space interface{}, tuple interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().InsertAsync(space, tuple)
+	return connMulti.anyMode.InsertAsync(space, tuple)
 }
 
 func (connMulti *ConnectionMulti) ReplaceAsync(space interface{}, tuple interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().ReplaceAsync(space, tuple)
+	return connMulti.anyMode.ReplaceAsync(space, tuple)
 }
 
 func (connMulti *ConnectionMulti) DeleteAsync(space, index interface{}, key interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().DeleteAsync(space, index, key)
+	return connMulti.anyMode.DeleteAsync(space, index, key)
 }
 
 func (connMulti *ConnectionMulti) UpdateAsync(space, index interface{}, key, ops interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().UpdateAsync(space, index, key, ops)
+	return connMulti.anyMode.UpdateAsync(space, index, key, ops)
 }
 
 func (connMulti *ConnectionMulti) UpsertAsync(space interface{}, tuple interface{}, ops interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().UpsertAsync(space, tuple, ops)
+	return connMulti.anyMode.UpsertAsync(space, tuple, ops)
 }
 
 func (connMulti *ConnectionMulti) CallAsync(functionName string, args interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().CallAsync(functionName, args)
+	return connMulti.anyMode.CallAsync(functionName, args)
 }
 
 func (connMulti *ConnectionMulti) Call17Async(functionName string, args interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().Call17Async(functionName, args)
+	return connMulti.anyMode.Call17Async(functionName, args)
 }
 
 func (connMulti *ConnectionMulti) EvalAsync(expr string, args interface{}) *tarantool.Future {
-	return connMulti.getCurrentConnection().EvalAsync(expr, args)
+	return connMulti.anyMode.EvalAsync(expr, args)
 }
@@ -3,6 +3,9 @@ package multi
 import (
 	"log"
 	"os"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -208,6 +211,691 @@ func TestRefresh(t *testing.T) {
 	}
 }
 
+func TestStats(t *testing.T) {
+	multiConn, _ := Connect([]string{server1, server2}, connOpts)
+	if multiConn == nil {
+		t.Errorf("conn is nil after Connect")
+		return
+	}
+	defer multiConn.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := multiConn.Ping(); err != nil {
+			t.Fatalf("Failed to Ping: %s", err.Error())
+		}
+	}
+
+	stats := multiConn.Stats()
+	if len(stats.ByAddr) != 2 {
+		t.Fatalf("Unexpected stats addresses: %v", stats.ByAddr)
+	}
+	if stats.ByAddr[server1].Requests[tarantool.PingRequest] < 3 {
+		t.Errorf("Unexpected Ping requests count on %s: %v", server1, stats.ByAddr[server1].Requests)
+	}
+	var pings, bytesRead uint64
+	for _, addrStats := range stats.ByAddr {
+		pings += addrStats.Requests[tarantool.PingRequest]
+		bytesRead += addrStats.BytesRead
+	}
+	if stats.Requests[tarantool.PingRequest] != pings || stats.BytesRead != bytesRead {
+		t.Errorf("Stats are not aggregated: %+v", stats.Stats)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	var mutex sync.Mutex
+	addrs := make(map[string]int)
+
+	opts := connOpts
+	opts.Interceptors = []tarantool.Interceptor{{
+		OnComplete: func(info *tarantool.RequestInfo, resp *tarantool.Response, err error, latency time.Duration) {
+			if info.Code != tarantool.EvalRequest {
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			addrs[info.Addr]++
+		},
+	}}
+	multiConn, _ := Connect([]string{server1, server2}, opts)
+	if multiConn == nil {
+		t.Errorf("conn is nil after Connect")
+		return
+	}
+	defer multiConn.Close()
+
+	if _, err := multiConn.Eval("return 1", []interface{}{}); err != nil {
+		t.Fatalf("Failed to Eval: %s", err.Error())
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if addrs[server1] != 1 {
+		t.Errorf("Request is not intercepted: %v", addrs)
+	}
+}
+
+func TestModes(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	setReadOnly := func(addr string, ro bool) {
+		conn, _ := multiConn.getConnectionFromPool(addr)
+		if _, err := conn.Eval("box.cfg{read_only = ...}", []interface{}{ro}); err != nil {
+			t.Fatalf("Failed to set read_only: %s", err.Error())
+		}
+	}
+	setReadOnly(server2, true)
+	defer setReadOnly(server2, false)
+	// role change is picked up within check interval
+	time.Sleep(300 * time.Millisecond)
+
+	if role := multiConn.Role(server1); role != MasterRole {
+		t.Errorf("Unexpected role of %s: %d", server1, role)
+	}
+	if role := multiConn.Role(server2); role != ReplicaRole {
+		t.Errorf("Unexpected role of %s: %d", server2, role)
+	}
+
+	var listen []string
+	for mode, expected := range map[Mode]string{
+		ANY:      server1,
+		RW:       server1,
+		RO:       server2,
+		PreferRW: server1,
+		PreferRO: server2,
+	} {
+		err := multiConn.WithMode(mode).EvalTyped("return box.cfg.listen", []interface{}{}, &listen)
+		if err != nil {
+			t.Fatalf("Failed to Eval in mode %d: %s", mode, err.Error())
+		}
+		if len(listen) != 1 || listen[0] != expected {
+			t.Errorf("Request in mode %d is sent to %v, expected %s", mode, listen, expected)
+		}
+	}
+
+	// writes are sent to master in any mode
+	roConn := multiConn.WithMode(RO)
+	if _, err := roConn.Replace("test", []interface{}{uint(1), "modes"}); err != nil {
+		t.Errorf("Failed to Replace in RO mode: %s", err.Error())
+	}
+
+	// there is no master after failover to read-only instance
+	setReadOnly(server1, true)
+	defer setReadOnly(server1, false)
+	time.Sleep(300 * time.Millisecond)
+	if _, err := multiConn.WithMode(RW).Ping(); err != ErrNoRwConnection {
+		t.Errorf("Unexpected error in RW mode without master: %v", err)
+	}
+	if _, err := multiConn.WithMode(PreferRW).Ping(); err != nil {
+		t.Errorf("Failed to Ping in PreferRW mode without master: %s", err.Error())
+	}
+}
+
+func TestBalancers(t *testing.T) {
+	nodes := []Node{
+		{Addr: server1, RTT: time.Millisecond},
+		{Addr: server2, RTT: 100 * time.Millisecond},
+	}
+	rr := &RoundRobinBalancer{}
+	for i := 0; i < 4; i++ {
+		if n := rr.Choose(nodes); n != i%2 {
+			t.Errorf("Unexpected round-robin choice %d: %d", i, n)
+		}
+	}
+
+	counts := make([]int, len(nodes))
+	for i := 0; i < 1000; i++ {
+		counts[LatencyBalancer{}.Choose(nodes)]++
+	}
+	if counts[0] < 900 || counts[1] == 0 {
+		t.Errorf("Unexpected latency-weighted choices: %v", counts)
+	}
+
+	counts = make([]int, len(nodes))
+	for i := 0; i < 1000; i++ {
+		counts[RandomBalancer{}.Choose(nodes)]++
+	}
+	if counts[0] == 0 || counts[1] == 0 {
+		t.Errorf("Unexpected random choices: %v", counts)
+	}
+}
+
+type outOfRangeBalancer struct{}
+
+func (outOfRangeBalancer) Choose(nodes []Node) int {
+	return len(nodes)
+}
+
+func TestBalancerOutOfRange(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: time.Second,
+		Balancer:     outOfRangeBalancer{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	var listen []string
+	if err := multiConn.EvalTyped("return box.cfg.listen", []interface{}{}, &listen); err != nil {
+		t.Fatalf("Failed to Eval: %s", err.Error())
+	}
+	if len(listen) != 1 || listen[0] != server1 {
+		t.Errorf("Request is sent to %v, expected %s", listen, server1)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: time.Second,
+		Balancer:     &RoundRobinBalancer{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	counts := make(map[string]int)
+	var listen []string
+	for i := 0; i < 10; i++ {
+		if err := multiConn.EvalTyped("return box.cfg.listen", []interface{}{}, &listen); err != nil {
+			t.Fatalf("Failed to Eval: %s", err.Error())
+		}
+		counts[listen[0]]++
+	}
+	if counts[server1] != 5 || counts[server2] != 5 {
+		t.Errorf("Requests are not balanced: %v", counts)
+	}
+}
+
+// eventRecorder collects pool events until pool is closed.
+type eventRecorder struct {
+	mutex  sync.Mutex
+	events []PoolEvent
+	closed chan struct{}
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{closed: make(chan struct{})}
+}
+
+func (r *eventRecorder) handle(event PoolEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+	if event.Kind == PoolClosed {
+		close(r.closed)
+	}
+}
+
+// wait returns all events after pool is closed.
+func (r *eventRecorder) wait(t *testing.T) []PoolEvent {
+	select {
+	case <-r.closed:
+	case <-time.After(time.Second):
+		t.Fatalf("PoolClosed event is not received")
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.events
+}
+
+func TestAddRemove(t *testing.T) {
+	recorder := newEventRecorder()
+	multiConn, err := ConnectWithOpts([]string{server1}, connOpts, OptsMulti{
+		CheckTimeout: time.Second,
+		OnEvent:      recorder.handle,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+
+	if err := multiConn.Add(server2, connOpts); err != nil {
+		t.Fatalf("Failed to add %s: %s", server2, err.Error())
+	}
+	if err := multiConn.Add(server2, connOpts); err != ErrAddrExists {
+		t.Errorf("Unexpected error on second add: %v", err)
+	}
+	if err := multiConn.Add("err", connOpts); err == nil {
+		t.Errorf("Unreachable address is added")
+	}
+	conn, ok := multiConn.getConnectionFromPool(server2)
+	if !ok || !conn.ConnectedNow() {
+		t.Fatalf("Added address is not connected")
+	}
+
+	// requests in flight are completed before connection is closed
+	fut := conn.EvalAsync("require('fiber').sleep(0.2) return 1", []interface{}{})
+	if err := multiConn.Remove(server2); err != nil {
+		t.Errorf("Failed to remove %s: %s", server2, err.Error())
+	}
+	if _, err := fut.Get(); err != nil {
+		t.Errorf("Request in flight failed: %s", err.Error())
+	}
+	if !conn.ClosedNow() {
+		t.Errorf("Connection to removed address is not closed")
+	}
+	if err := multiConn.Remove(server2); err != ErrAddrNotFound {
+		t.Errorf("Unexpected error on second remove: %v", err)
+	}
+	if _, ok := multiConn.getConnectionFromPool(server2); ok {
+		t.Errorf("Removed address is in pool")
+	}
+
+	multiConn.Close()
+	var kinds []PoolEventKind
+	for _, event := range recorder.wait(t) {
+		if event.Addr == server2 {
+			kinds = append(kinds, event.Kind)
+		}
+	}
+	expected := []PoolEventKind{NodeAdded, NodeUp, NodeRemoved}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Unexpected events of %s: %v, expected %v", server2, kinds, expected)
+	}
+}
+
+type mutableDiscoverer struct {
+	mutex     sync.Mutex
+	instances []Instance
+}
+
+func (d *mutableDiscoverer) set(instances []Instance) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.instances = instances
+}
+
+func (d *mutableDiscoverer) Discover(conn tarantool.Connector) ([]Instance, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.instances, nil
+}
+
+func TestDiscoveryRemoveInFlight(t *testing.T) {
+	opts := connOpts
+	opts.Timeout = 0
+	discoverer := &mutableDiscoverer{instances: []Instance{{Addr: server1}, {Addr: server2}}}
+	multiConn, err := ConnectWithOpts([]string{server1}, opts, OptsMulti{
+		CheckTimeout: time.Hour,
+		Discoverer:   discoverer,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	conn, _ := multiConn.getConnectionFromPool(server2)
+	fut := conn.EvalAsync("require('fiber').sleep(1) return 1", []interface{}{})
+
+	// discovery does not wait for requests to removed instance
+	discoverer.set([]Instance{{Addr: server1}})
+	start := time.Now()
+	multiConn.discover()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Discovery is blocked by request in flight for %s", elapsed)
+	}
+	if addrs := multiConn.getAddrs(); !reflect.DeepEqual(addrs, []string{server1}) {
+		t.Errorf("Instance is not removed: %v", addrs)
+	}
+	if _, err := fut.Get(); err != nil {
+		t.Errorf("Request to removed instance failed: %s", err.Error())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	recorder := newEventRecorder()
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: 100 * time.Millisecond,
+		OnEvent:      recorder.handle,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+
+	status := multiConn.Status()
+	if len(status) != 2 {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+	for i, addr := range []string{server1, server2} {
+		s := status[i]
+		if s.Addr != addr || s.State != NodeStateUp || s.Role != MasterRole ||
+			s.LastError != nil || s.LastChecked.IsZero() || s.Active != (addr == server1) {
+			t.Errorf("Unexpected status of %s: %+v", addr, s)
+		}
+	}
+
+	// active instance is switched after it is removed
+	if err := multiConn.Remove(server1); err != nil {
+		t.Fatalf("Failed to remove %s: %s", server1, err.Error())
+	}
+	if status := multiConn.Status(); len(status) != 1 || !status[0].Active {
+		t.Errorf("Unexpected status after switch: %+v", status)
+	}
+	multiConn.Close()
+	var switched []string
+	for _, event := range recorder.wait(t) {
+		if event.Kind == ActiveSwitched {
+			switched = append(switched, event.Addr)
+		}
+	}
+	expected := []string{server1, server2}
+	if !reflect.DeepEqual(switched, expected) {
+		t.Errorf("Unexpected switches of active instance: %v, expected %v",
+			switched, expected)
+	}
+}
+
+func TestStatusRoundRobin(t *testing.T) {
+	recorder := newEventRecorder()
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: 100 * time.Millisecond,
+		Balancer:     &RoundRobinBalancer{},
+		OnEvent:      recorder.handle,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+
+	// there is no active instance, requests are balanced between all
+	for _, s := range multiConn.Status() {
+		if s.Active {
+			t.Errorf("Unexpected active instance: %+v", s)
+		}
+	}
+	multiConn.Close()
+	for _, event := range recorder.wait(t) {
+		if event.Kind == ActiveSwitched {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	}
+}
+
+func TestRedirectReadonly(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	// switchover is not detected by check yet
+	conn, _ := multiConn.getConnectionFromPool(server1)
+	if _, err := conn.Eval("box.cfg{read_only = true}", []interface{}{}); err != nil {
+		t.Fatalf("Failed to set read_only: %s", err.Error())
+	}
+	defer conn.Eval("box.cfg{read_only = false}", []interface{}{})
+
+	if _, err := multiConn.Replace("test", []interface{}{uint(2), "redirect"}); err != nil {
+		t.Errorf("Write is not redirected: %s", err.Error())
+	}
+	if role := multiConn.Role(server1); role != ReplicaRole {
+		t.Errorf("Unexpected role of %s after redirect: %d", server1, role)
+	}
+	if _, err := multiConn.ReplaceAsync("test", []interface{}{uint(3), "redirect"}).Get(); err != nil {
+		t.Errorf("Async write failed: %s", err.Error())
+	}
+}
+
+func TestRedirectStaleRole(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	conn, _ := multiConn.getConnectionFromPool(server1)
+	if _, err := conn.Eval("box.cfg{read_only = true}", []interface{}{}); err != nil {
+		t.Fatalf("Failed to set read_only: %s", err.Error())
+	}
+	defer conn.Eval("box.cfg{read_only = false}", []interface{}{})
+
+	for _, mode := range []Mode{ANY, RW} {
+		// switchover from server1 to server2 is not detected by check yet
+		multiConn.mutex.Lock()
+		for addr, role := range map[string]Role{server1: MasterRole, server2: ReplicaRole} {
+			info := multiConn.nodes[addr]
+			info.role = role
+			multiConn.nodes[addr] = info
+		}
+		multiConn.mutex.Unlock()
+
+		if _, err := multiConn.WithMode(mode).Replace("test", []interface{}{uint(5), "stale"}); err != nil {
+			t.Errorf("Write in mode %d is not redirected to new master: %s", mode, err.Error())
+		}
+		if role := multiConn.Role(server2); role != MasterRole {
+			t.Errorf("Unexpected role of %s after redirect in mode %d: %d", server2, mode, role)
+		}
+	}
+}
+
+func TestWriteUnknownRole(t *testing.T) {
+	multiConn, err := ConnectWithOpts([]string{server1, server2}, connOpts, OptsMulti{
+		CheckTimeout: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	// role is reset on reconnect or check could fail
+	multiConn.mutex.Lock()
+	for addr, info := range multiConn.nodes {
+		info.role = UnknownRole
+		multiConn.nodes[addr] = info
+	}
+	multiConn.mutex.Unlock()
+
+	if _, err := multiConn.Replace("test", []interface{}{uint(4), "unknown"}); err != nil {
+		t.Errorf("Failed to write to instance with unknown role: %s", err.Error())
+	}
+	if _, err := multiConn.WithMode(PreferRO).ReplaceAsync("test", []interface{}{uint(4), "unknown"}).Get(); err != nil {
+		t.Errorf("Failed to write async to instance with unknown role: %s", err.Error())
+	}
+	if _, err := multiConn.WithMode(RW).Replace("test", []interface{}{uint(4), "unknown"}); err != ErrNoRwConnection {
+		t.Errorf("Unexpected error in RW mode without known master: %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	discoverer := functionDiscoverer{"get_cluster_nodes"}
+	instances, err := Discover([]string{"err", server1}, connOpts, discoverer)
+	if err != nil {
+		t.Fatalf("Failed to discover: %s", err.Error())
+	}
+	expected := []Instance{{Addr: "localhost:3013"}, {Addr: "localhost:3014"}}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Unexpected instances: %+v, expected %+v", instances, expected)
+	}
+
+	multiConn, err := ConnectWithOpts([]string{server2}, connOpts, OptsMulti{
+		CheckTimeout: 1 * time.Second,
+		Discoverer:   discoverer,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+	addrs := multiConn.getAddrs()
+	if !reflect.DeepEqual(addrs, []string{"localhost:3013", "localhost:3014"}) {
+		t.Errorf("Pool is not bootstrapped with discovered instances: %v", addrs)
+	}
+
+	if _, err := Discover([]string{"err"}, connOpts, discoverer); err == nil {
+		t.Errorf("err is nil with unavailable seeds")
+	}
+}
+
+// fakeClusterModules replaces config and cartridge modules with fakes
+// describing the same cluster.
+const fakeClusterModules = `
+rawset(_G, 'saved_modules', {
+    config = package.loaded['config'],
+    cartridge = package.loaded['cartridge'],
+})
+local instances = {
+    i1 = {rs = 'rs1', group = 'g1', advertise = 'localhost:3301', mode = 'rw'},
+    i2 = {rs = 'rs1', group = 'g1', listen = {{uri = 'localhost:3302'}}, mode = 'ro'},
+    i3 = {rs = 'rs2', group = 'g1'},
+    i4 = {rs = 'rs2', group = 'g1', advertise = 'localhost:3304'},
+}
+package.loaded['config'] = {
+    instances = function(self)
+        local res = {}
+        for name, i in pairs(instances) do
+            res[name] = {instance_name = name, replicaset_name = i.rs, group_name = i.group}
+        end
+        return res
+    end,
+    get = function(self, path, opts)
+        local i = instances[opts.instance]
+        if path == 'iproto.advertise.client' then
+            return i.advertise
+        elseif path == 'iproto.listen' then
+            return i.listen
+        elseif path == 'database.mode' then
+            return i.mode
+        end
+    end,
+}
+local rs = {uuid = 'rs1-uuid', alias = 'rs1', master = {uuid = 's2'}, active_master = {uuid = 's1'}}
+package.loaded['cartridge'] = {
+    admin_get_servers = function()
+        return {
+            {uri = 'localhost:3301', uuid = 's1', replicaset = rs},
+            {uri = 'localhost:3302', uuid = 's2', replicaset = rs},
+            {uri = 'localhost:3303', uuid = 's3', replicaset = rs, disabled = true},
+            {uri = 'localhost:3304', uuid = 's4'},
+        }
+    end,
+}
+`
+
+const restoreClusterModules = `
+package.loaded['config'] = saved_modules.config
+package.loaded['cartridge'] = saved_modules.cartridge
+`
+
+func TestClusterDiscoverers(t *testing.T) {
+	conn, err := tarantool.Connect(server1, connOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+	if _, err := conn.Eval(fakeClusterModules, []interface{}{}); err != nil {
+		t.Fatalf("Failed to replace modules: %s", err.Error())
+	}
+	defer conn.Eval(restoreClusterModules, []interface{}{})
+
+	byAddr := func(instances []Instance) []Instance {
+		sort.Slice(instances, func(i, j int) bool {
+			return instances[i].Addr < instances[j].Addr
+		})
+		return instances
+	}
+	cases := []struct {
+		discoverer Discoverer
+		expected   []Instance
+	}{
+		{ConfigDiscoverer{}, []Instance{
+			{Addr: "localhost:3301", Replicaset: "rs1", Role: MasterRole},
+			{Addr: "localhost:3302", Replicaset: "rs1", Role: ReplicaRole},
+			{Addr: "localhost:3304", Replicaset: "rs2", Role: UnknownRole},
+		}},
+		{ConfigDiscoverer{Replicaset: "rs2"}, []Instance{
+			{Addr: "localhost:3304", Replicaset: "rs2", Role: UnknownRole},
+		}},
+		{CartridgeDiscoverer{}, []Instance{
+			{Addr: "localhost:3301", Replicaset: "rs1-uuid", Role: MasterRole},
+			{Addr: "localhost:3302", Replicaset: "rs1-uuid", Role: ReplicaRole},
+		}},
+		{CartridgeDiscoverer{Replicaset: "rs1"}, []Instance{
+			{Addr: "localhost:3301", Replicaset: "rs1-uuid", Role: MasterRole},
+			{Addr: "localhost:3302", Replicaset: "rs1-uuid", Role: ReplicaRole},
+		}},
+	}
+	for _, c := range cases {
+		instances, err := Discover([]string{server1}, connOpts, c.discoverer)
+		if err != nil {
+			t.Errorf("Failed to discover with %#v: %s", c.discoverer, err.Error())
+			continue
+		}
+		if instances = byAddr(instances); !reflect.DeepEqual(instances, c.expected) {
+			t.Errorf("Unexpected instances discovered with %#v: %+v, expected %+v",
+				c.discoverer, instances, c.expected)
+		}
+	}
+}
+
+type staticDiscoverer []Instance
+
+func (d staticDiscoverer) Discover(conn tarantool.Connector) ([]Instance, error) {
+	return d, nil
+}
+
+func TestConfiguredRoles(t *testing.T) {
+	unavailable := "127.0.0.1:3015"
+	multiConn, err := ConnectWithOpts([]string{server1}, connOpts, OptsMulti{
+		CheckTimeout: time.Hour,
+		Discoverer: staticDiscoverer{
+			{Addr: server1, Role: ReplicaRole},
+			{Addr: unavailable, Role: MasterRole},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer multiConn.Close()
+
+	// configured role is used until instance is checked
+	if role := multiConn.Role(unavailable); role != MasterRole {
+		t.Errorf("Unexpected role of unavailable instance: %d", role)
+	}
+	if role := multiConn.Role(server1); role != MasterRole {
+		t.Errorf("Configured role is not overridden by check: %d", role)
+	}
+}
+
+func TestParseInstances(t *testing.T) {
+	resp := [][][]string{{
+		{"localhost:3301", "uuid1", "s1", "master"},
+		{"localhost:3302", "uuid1", "s1", "replica"},
+		{"localhost:3303", "uuid2", "s2", ""},
+	}}
+	instances, err := parseInstances(resp, "")
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err.Error())
+	}
+	expected := []Instance{
+		{Addr: "localhost:3301", Replicaset: "uuid1", Role: MasterRole},
+		{Addr: "localhost:3302", Replicaset: "uuid1", Role: ReplicaRole},
+		{Addr: "localhost:3303", Replicaset: "uuid2", Role: UnknownRole},
+	}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Errorf("Unexpected instances: %+v, expected %+v", instances, expected)
+	}
+
+	// replicaset is matched by uuid or alias
+	for _, rs := range []string{"uuid2", "s2"} {
+		instances, _ = parseInstances(resp, rs)
+		if !reflect.DeepEqual(instances, expected[2:]) {
+			t.Errorf("Unexpected instances of %s: %+v", rs, instances)
+		}
+	}
+
+	if _, err := parseInstances([][][]string{{{"localhost:3301"}}}, ""); err == nil {
+		t.Errorf("err is nil with malformed response")
+	}
+}
+
 // runTestMain is a body of TestMain function
 // (see https://pkg.go.dev/testing#hdr-Main).
 // Using defer + os.Exit is not works so TestMain body
@@ -0,0 +1,112 @@
+package multi
+
+import (
+	"github.com/tarantool/go-tarantool"
+)
+
+// defaultMaxRedirects is a default value of OptsMulti.MaxRedirects.
+const defaultMaxRedirects = 2
+
+// isReadOnlyError reports if request is rejected because instance is not
+// writable. Such request is not applied, so it could be resent.
+func isReadOnlyError(err error) bool {
+	if err, ok := err.(tarantool.Error); ok {
+		return err.Code == tarantool.ErrReadonly || err.Code == tarantool.ErrNonmaster
+	}
+	return false
+}
+
+// redirect marks instance, which rejected write with err, as replica and
+// returns other instance matching mode to resend request to. Roles are
+// updated by check only every OptsMulti.CheckTimeout, so right after
+// switchover new master could still be recorded as replica. If there is
+// no suitable instance, roles of other instances are rechecked.
+func (connMulti *ConnectionMulti) redirect(conn *tarantool.Connection, err error, mode Mode) (*tarantool.Connection, bool) {
+	if !isReadOnlyError(err) {
+		return nil, false
+	}
+	connMulti.markReplica(conn)
+	next, err := connMulti.getConnection(mode)
+	if err != nil || next == conn {
+		connMulti.recheckRoles(conn)
+		next, err = connMulti.getConnection(mode)
+	}
+	if err != nil || next == conn {
+		return nil, false
+	}
+	return next, true
+}
+
+// recheckRoles checks connected instances except one with conn, which are
+// not recorded as masters.
+func (connMulti *ConnectionMulti) recheckRoles(except *tarantool.Connection) {
+	for _, addr := range connMulti.getAddrs() {
+		conn, ok := connMulti.getConnectionFromPool(addr)
+		if !ok || conn == except || !conn.ConnectedNow() || connMulti.Role(addr) == MasterRole {
+			continue
+		}
+		connMulti.checkNode(addr, conn)
+	}
+}
+
+// markReplica sets role of instance until next check.
+func (connMulti *ConnectionMulti) markReplica(conn *tarantool.Connection) {
+	connMulti.mutex.Lock()
+	defer connMulti.mutex.Unlock()
+	for addr, c := range connMulti.pool {
+		if c == conn {
+			info := connMulti.nodes[addr]
+			info.role = ReplicaRole
+			connMulti.nodes[addr] = info
+			return
+		}
+	}
+}
+
+// write sends data modification request to writable instance. If instance
+// turns out to be read-only (after switchover or if its role is not known
+// yet), request is resent to other instance at most OptsMulti.MaxRedirects
+// times.
+func (c *ModeConnection) write(do func(conn *tarantool.Connection) error) error {
+	conn, err := c.pool.getConnection(c.writeMode)
+	if err != nil {
+		return err
+	}
+	for redirects := 0; ; redirects++ {
+		err = do(conn)
+		if redirects >= c.pool.opts.MaxRedirects {
+			return err
+		}
+		next, ok := c.pool.redirect(conn, err, c.writeMode)
+		if !ok {
+			return err
+		}
+		conn = next
+	}
+}
+
+// writeAsync is an asynchronous version of write. Result of request is
+// awaited in separate goroutine to resend it, so future of connection is
+// returned as is if redirects are disabled.
+func (c *ModeConnection) writeAsync(send func(conn *tarantool.Connection) *tarantool.Future) *tarantool.Future {
+	conn, err := c.pool.getConnection(c.writeMode)
+	if err != nil {
+		return tarantool.NewErrorFuture(err)
+	}
+	if c.pool.opts.MaxRedirects <= 0 {
+		return send(conn)
+	}
+	redirects := 0
+	return tarantool.ChainFuture(send(conn), func(err error) *tarantool.Future {
+		if redirects >= c.pool.opts.MaxRedirects {
+			return nil
+		}
+		next, ok := c.pool.redirect(conn, err, c.writeMode)
+		if !ok {
+			return nil
+		}
+		redirects++
+		conn = next
+		return send(conn)
+	})
+}
@@ -0,0 +1,169 @@
+package multi
+
+import (
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// NodeState is a state of instance connection.
+type NodeState int
+
+const (
+	// NodeStateDown is a state of instance, which is not connected or failed
+	// last check.
+	NodeStateDown NodeState = iota
+	// NodeStateUp is a state of connected instance, which passed last check.
+	NodeStateUp
+)
+
+// NodeStatus is a status of instance in pool.
+type NodeStatus struct {
+	Addr  string
+	State NodeState
+	Role  Role
+	// Active reports if instance is the first connected one, so requests
+	// are sent to it by FirstBalancer. It is always false with other
+	// balancers.
+	Active bool
+	// LastError is an error of last failed connect or check. It is nil
+	// if last check is passed.
+	LastError error
+	// LastChecked is a time of last connect or check.
+	LastChecked time.Time
+}
+
+// nodeInfo is a result of instance checks.
+type nodeInfo struct {
+	role    Role
+	rtt     time.Duration
+	up      bool
+	err     error
+	checked time.Time
+}
+
+// Status returns statuses of instances in order of pool addresses.
+func (connMulti *ConnectionMulti) Status() []NodeStatus {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+
+	statuses := make([]NodeStatus, 0, len(connMulti.addrs))
+	for _, addr := range connMulti.addrs {
+		info := connMulti.nodes[addr]
+		status := NodeStatus{
+			Addr:        addr,
+			Role:        info.role,
+			Active:      addr == connMulti.active,
+			LastError:   info.err,
+			LastChecked: info.checked,
+		}
+		if info.up {
+			status.State = NodeStateUp
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// resetNode forgets role and RTT of instance, which connection is replaced.
+// Role from cluster configuration is used until next check. It should be
+// called under lock.
+func (connMulti *ConnectionMulti) resetNode(addr string) {
+	if info, ok := connMulti.nodes[addr]; ok {
+		info.role = connMulti.configured[addr]
+		info.rtt = 0
+		connMulti.nodes[addr] = info
+	}
+}
+
+// updateNode stores result of instance check and sends event if instance
+// goes up or down.
+func (connMulti *ConnectionMulti) updateNode(addr string, conn *tarantool.Connection, info nodeInfo) {
+	connMulti.mutex.Lock()
+	if conn != nil && connMulti.pool[addr] != conn || indexOf(addr, connMulti.addrs) < 0 {
+		// connection is replaced or instance is removed during check
+		connMulti.mutex.Unlock()
+		return
+	}
+	wasUp := connMulti.nodes[addr].up
+	connMulti.nodes[addr] = info
+	connMulti.mutex.Unlock()
+
+	if info.up && !wasUp {
+		connMulti.sendEvent(NodeUp, addr)
+	} else if !info.up && wasUp {
+		connMulti.sendEvent(NodeDown, addr)
+	}
+}
+
+// markDown stores error of connect to instance. Role from cluster
+// configuration is used until instance is connected and checked.
+func (connMulti *ConnectionMulti) markDown(addr string, err error) {
+	info := nodeInfo{role: connMulti.configuredRole(addr), err: err, checked: time.Now()}
+	connMulti.updateNode(addr, nil, info)
+}
+
+// checkNode checks instance with ping and updates its role and RTT. Role
+// from cluster configuration is used if box.info.ro fails.
+func (connMulti *ConnectionMulti) checkNode(addr string, conn *tarantool.Connection) {
+	info := nodeInfo{checked: time.Now()}
+	if _, info.err = conn.Ping(); info.err == nil {
+		info.up = true
+		info.rtt = time.Since(info.checked)
+		var ro []bool
+		if err := conn.EvalTyped("return box.info.ro", []interface{}{}, &ro); err == nil && len(ro) == 1 {
+			if ro[0] {
+				info.role = ReplicaRole
+			} else {
+				info.role = MasterRole
+			}
+		} else {
+			info.role = connMulti.configuredRole(addr)
+		}
+	}
+	connMulti.updateNode(addr, conn, info)
+}
+
+// configuredRole returns role of instance in cluster configuration.
+func (connMulti *ConnectionMulti) configuredRole(addr string) Role {
+	connMulti.mutex.RLock()
+	defer connMulti.mutex.RUnlock()
+	return connMulti.configured[addr]
+}
+
+// checkNodes checks all instances with connection.
+func (connMulti *ConnectionMulti) checkNodes() {
+	for _, addr := range connMulti.getAddrs() {
+		if connMulti.getState() == connClosed {
+			return
+		}
+		if conn, ok := connMulti.getConnectionFromPool(addr); ok {
+			connMulti.checkNode(addr, conn)
+		}
+	}
+}
+
+// updateActive sends event if first connected instance is changed. Other
+// balancers do not prefer first instance, so it is not tracked for them.
+func (connMulti *ConnectionMulti) updateActive() {
+	switch connMulti.balancer.(type) {
+	case FirstBalancer, *FirstBalancer:
+	default:
+		return
+	}
+	connMulti.mutex.Lock()
+	active := ""
+	for _, addr := range connMulti.addrs {
+		if conn := connMulti.pool[addr]; conn != nil && conn.ConnectedNow() {
+			active = addr
+			break
+		}
+	}
+	switched := active != connMulti.active
+	connMulti.active = active
+	connMulti.mutex.Unlock()
+
+	if switched {
+		connMulti.sendEvent(ActiveSwitched, active)
+	}
+}
@@ -0,0 +1,404 @@
+// Package prometheus exports statistics of tarantool connections as
+// Prometheus metrics.
+//
+// Collector produces metrics in Prometheus text exposition format by itself,
+// so the package has no dependencies on Prometheus client libraries. Use
+// Collector as http.Handler to serve metrics:
+//
+//	collector := prometheus.NewCollector()
+//	collector.Register(conn)
+//	http.Handle("/metrics", collector)
+//
+// Metrics are labeled by address of Tarantool instance and by Opts.Handle
+// of connection (formatted with fmt.Sprint). Statistics of distinct
+// connections with the same labels are summed.
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tarantool/go-tarantool"
+	"github.com/tarantool/go-tarantool/multi"
+)
+
+// ContentType is a content type of text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultNamespace is a prefix of metric names.
+const DefaultNamespace = "tarantool_client"
+
+// requestNames are values of "request" label.
+var requestNames = map[int32]string{
+	tarantool.SelectRequest:    "select",
+	tarantool.InsertRequest:    "insert",
+	tarantool.ReplaceRequest:   "replace",
+	tarantool.UpdateRequest:    "update",
+	tarantool.DeleteRequest:    "delete",
+	tarantool.CallRequest:      "call",
+	tarantool.AuthRequest:      "auth",
+	tarantool.EvalRequest:      "eval",
+	tarantool.UpsertRequest:    "upsert",
+	tarantool.Call17Request:    "call17",
+	tarantool.PingRequest:      "ping",
+	tarantool.SubscribeRequest: "subscribe",
+}
+
+// Collector collects statistics of registered connections.
+// Connections are unregistered when they are closed.
+type Collector struct {
+	// Namespace is a prefix of metric names, DefaultNamespace is used
+	// if it is empty.
+	Namespace string
+
+	mutex sync.Mutex
+	conns []registeredConn
+	multi []registeredMulti
+}
+
+type registeredConn struct {
+	conn        *tarantool.Connection
+	unsubscribe func()
+}
+
+type registeredMulti struct {
+	connMulti *multi.ConnectionMulti
+	stop      chan struct{}
+}
+
+// NewCollector creates collector with default namespace.
+func NewCollector() *Collector {
+	return &Collector{Namespace: DefaultNamespace}
+}
+
+// Register adds connection to collector.
+func (c *Collector) Register(conn *tarantool.Connection) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, registered := range c.conns {
+		if registered.conn == conn {
+			return
+		}
+	}
+	unsubscribe := conn.OnEvent(func(event tarantool.ConnEvent) {
+		if event.Kind == tarantool.Closed {
+			c.Unregister(conn)
+		}
+	})
+	if conn.ClosedNow() {
+		unsubscribe()
+		return
+	}
+	c.conns = append(c.conns, registeredConn{conn, unsubscribe})
+}
+
+// RegisterMulti adds connection pool to collector. Metrics of pool are
+// reported by address of each connection of pool.
+func (c *Collector) RegisterMulti(connMulti *multi.ConnectionMulti) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, registered := range c.multi {
+		if registered.connMulti == connMulti {
+			return
+		}
+	}
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-connMulti.Done():
+			c.UnregisterMulti(connMulti)
+		case <-stop:
+		}
+	}()
+	c.multi = append(c.multi, registeredMulti{connMulti, stop})
+}
+
+// Unregister removes connection from collector.
+func (c *Collector) Unregister(conn *tarantool.Connection) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, registered := range c.conns {
+		if registered.conn == conn {
+			registered.unsubscribe()
+			c.conns = append(c.conns[:i], c.conns[i+1:]...)
+			return
+		}
+	}
+}
+
+// UnregisterMulti removes connection pool from collector.
+func (c *Collector) UnregisterMulti(connMulti *multi.ConnectionMulti) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, registered := range c.multi {
+		if registered.connMulti == connMulti {
+			close(registered.stop)
+			c.multi = append(c.multi[:i], c.multi[i+1:]...)
+			return
+		}
+	}
+}
+
+// source is statistics of one connection with its labels.
+type source struct {
+	addr      string
+	handle    string
+	connected bool
+	stats     tarantool.Stats
+}
+
+// collect takes statistics of registered connections. Connection registered
+// both directly and through pool is reported once.
+func (c *Collector) collect() (sources []source) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	type labels struct{ addr, handle string }
+	seen := make(map[*tarantool.Connection]bool)
+	indexes := make(map[labels]int)
+	add := func(conn *tarantool.Connection, addr, handle string) {
+		if seen[conn] {
+			return
+		}
+		seen[conn] = true
+		src := source{
+			addr:      addr,
+			handle:    handle,
+			connected: conn.ConnectedNow(),
+			stats:     conn.Stats(),
+		}
+		key := labels{addr, handle}
+		if i, ok := indexes[key]; ok {
+			sources[i].connected = sources[i].connected || src.connected
+			sources[i].stats.Add(src.stats)
+			return
+		}
+		indexes[key] = len(sources)
+		sources = append(sources, src)
+	}
+
+	for _, registered := range c.conns {
+		conn := registered.conn
+		if conn.ClosedNow() {
+			continue
+		}
+		add(conn, conn.Addr(), handleLabel(conn.Handle()))
+	}
+
+	for _, registered := range c.multi {
+		connMulti := registered.connMulti
+		if connMulti.ClosedNow() {
+			continue
+		}
+		handle := handleLabel(connMulti.Handle())
+		conns := connMulti.Connections()
+		addrs := make([]string, 0, len(conns))
+		for addr := range conns {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			add(conns[addr], addr, handle)
+		}
+	}
+	return
+}
+
+func handleLabel(handle interface{}) string {
+	if handle == nil {
+		return ""
+	}
+	return fmt.Sprint(handle)
+}
+
+// WriteTo writes metrics of registered connections in text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	return writeSources(w, c.namespace(), c.collect())
+}
+
+func (c *Collector) namespace() string {
+	if c.Namespace == "" {
+		return DefaultNamespace
+	}
+	return c.Namespace
+}
+
+// ServeHTTP serves metrics of registered connections.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	c.WriteTo(w)
+}
+
+// family is a metric family with its samples.
+type family struct {
+	name    string
+	typ     string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	suffix string
+	labels []string // pairs of name and value
+	value  float64
+}
+
+func (f *family) add(suffix string, value float64, labels ...string) {
+	f.samples = append(f.samples, sample{suffix: suffix, labels: labels, value: value})
+}
+
+func writeSources(w io.Writer, namespace string, sources []source) (int64, error) {
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].addr != sources[j].addr {
+			return sources[i].addr < sources[j].addr
+		}
+		return sources[i].handle < sources[j].handle
+	})
+
+	newFamily := func(name, typ, help string) *family {
+		return &family{name: namespace + "_" + name, typ: typ, help: help}
+	}
+	connected := newFamily("connected", "gauge", "Whether connection is established.")
+	requests := newFamily("requests_total", "counter", "Number of requests sent.")
+	responses := newFamily("responses_total", "counter", "Number of responses received.")
+	unexpected := newFamily("unexpected_responses_total", "counter", "Number of responses with unknown request id.")
+	errs := newFamily("errors_total", "counter", "Number of failed requests by error code.")
+	netErrs := newFamily("network_errors_total", "counter", "Number of requests failed due to network errors.")
+	timeouts := newFamily("timeouts_total", "counter", "Number of requests failed due to client timeout.")
+	rateLimited := newFamily("rate_limited_total", "counter", "Number of requests dropped due to rate limit.")
+	reconnects := newFamily("reconnects_total", "counter", "Number of successful reconnects.")
+	bytesRead := newFamily("read_bytes_total", "counter", "Number of bytes read from socket.")
+	bytesWritten := newFamily("written_bytes_total", "counter", "Number of bytes written to socket.")
+	inFlight := newFamily("in_flight_requests", "gauge", "Number of requests waiting for response.")
+	latency := newFamily("request_duration_seconds", "histogram", "Latency of requests.")
+
+	for _, src := range sources {
+		labels := []string{"addr", src.addr, "handle", src.handle}
+		with := func(name, value string) []string {
+			return append(labels[:len(labels):len(labels)], name, value)
+		}
+		stats := src.stats
+
+		if src.connected {
+			connected.add("", 1, labels...)
+		} else {
+			connected.add("", 0, labels...)
+		}
+		for _, code := range sortedCodes(stats.Requests) {
+			requests.add("", float64(stats.Requests[code]), with("request", requestName(code))...)
+		}
+		responses.add("", float64(stats.Responses), labels...)
+		unexpected.add("", float64(stats.UnexpectedResponses), labels...)
+		errCodes := make([]int, 0, len(stats.Errors))
+		for code := range stats.Errors {
+			errCodes = append(errCodes, int(code))
+		}
+		sort.Ints(errCodes)
+		for _, code := range errCodes {
+			errs.add("", float64(stats.Errors[uint32(code)]), with("code", strconv.Itoa(code))...)
+		}
+		netErrs.add("", float64(stats.NetworkErrors), labels...)
+		timeouts.add("", float64(stats.Timeouts), labels...)
+		rateLimited.add("", float64(stats.RateLimited), labels...)
+		reconnects.add("", float64(stats.Reconnects), labels...)
+		bytesRead.add("", float64(stats.BytesRead), labels...)
+		bytesWritten.add("", float64(stats.BytesWritten), labels...)
+		inFlight.add("", float64(stats.InFlightTotal()), labels...)
+
+		codes := make([]int32, 0, len(stats.Latency))
+		for code := range stats.Latency {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+		for _, code := range codes {
+			h := stats.Latency[code]
+			hlabels := with("request", requestName(code))
+			var cumulative uint64
+			for i, bound := range h.Bounds {
+				cumulative += h.Counts[i]
+				le := strconv.FormatFloat(bound.Seconds(), 'g', -1, 64)
+				latency.add("_bucket", float64(cumulative), append(hlabels[:len(hlabels):len(hlabels)], "le", le)...)
+			}
+			latency.add("_bucket", float64(h.Count), append(hlabels[:len(hlabels):len(hlabels)], "le", "+Inf")...)
+			latency.add("_sum", h.Sum.Seconds(), hlabels...)
+			latency.add("_count", float64(h.Count), hlabels...)
+		}
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, f := range []*family{connected, requests, responses, unexpected, errs, netErrs,
+		timeouts, rateLimited, reconnects, bytesRead, bytesWritten, inFlight, latency} {
+		if len(f.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(cw, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(cw, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range f.samples {
+			cw.WriteString(f.name + s.suffix)
+			if len(s.labels) > 0 {
+				cw.WriteString("{")
+				for i := 0; i < len(s.labels); i += 2 {
+					if i > 0 {
+						cw.WriteString(",")
+					}
+					cw.WriteString(s.labels[i] + `="` + escapeLabel(s.labels[i+1]) + `"`)
+				}
+				cw.WriteString("}")
+			}
+			cw.WriteString(" " + strconv.FormatFloat(s.value, 'g', -1, 64) + "\n")
+		}
+	}
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func sortedCodes(m map[int32]uint64) []int32 {
+	codes := make([]int32, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+func requestName(code int32) string {
+	if name, ok := requestNames[code]; ok {
+		return name
+	}
+	return strconv.Itoa(int(code))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// countingWriter counts written bytes and remembers first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+func (cw *countingWriter) WriteString(s string) {
+	cw.Write([]byte(s))
+}
@@ -0,0 +1,151 @@
+package prometheus
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+func TestWriteSources(t *testing.T) {
+	stats := tarantool.Stats{
+		Requests:  map[int32]uint64{tarantool.PingRequest: 3, tarantool.SelectRequest: 2},
+		Responses: 5,
+		Errors:    map[uint32]uint64{tarantool.ErrTimeouted: 1},
+		Timeouts:  1,
+		BytesRead: 100,
+		InFlight:  []int{1, 2},
+		Latency: map[int32]tarantool.LatencyHistogram{
+			tarantool.PingRequest: {
+				Bounds: []time.Duration{time.Millisecond, time.Second},
+				Counts: []uint64{2, 0, 1},
+				Count:  3,
+				Sum:    2 * time.Second,
+			},
+		},
+	}
+	sources := []source{
+		{addr: "127.0.0.1:3014", handle: `a "b"`, stats: tarantool.Stats{}},
+		{addr: "127.0.0.1:3013", handle: "main", connected: true, stats: stats},
+	}
+
+	var buf bytes.Buffer
+	n, err := writeSources(&buf, DefaultNamespace, sources)
+	if err != nil {
+		t.Fatalf("Failed to write metrics: %s", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("Unexpected written length %d, expected %d", n, buf.Len())
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE tarantool_client_requests_total counter",
+		`tarantool_client_connected{addr="127.0.0.1:3013",handle="main"} 1`,
+		`tarantool_client_connected{addr="127.0.0.1:3014",handle="a \"b\""} 0`,
+		`tarantool_client_requests_total{addr="127.0.0.1:3013",handle="main",request="select"} 2`,
+		`tarantool_client_requests_total{addr="127.0.0.1:3013",handle="main",request="ping"} 3`,
+		`tarantool_client_errors_total{addr="127.0.0.1:3013",handle="main",code="16387"} 1`,
+		`tarantool_client_timeouts_total{addr="127.0.0.1:3013",handle="main"} 1`,
+		`tarantool_client_in_flight_requests{addr="127.0.0.1:3013",handle="main"} 3`,
+		"# TYPE tarantool_client_request_duration_seconds histogram",
+		`tarantool_client_request_duration_seconds_bucket{addr="127.0.0.1:3013",handle="main",request="ping",le="0.001"} 2`,
+		`tarantool_client_request_duration_seconds_bucket{addr="127.0.0.1:3013",handle="main",request="ping",le="1"} 2`,
+		`tarantool_client_request_duration_seconds_bucket{addr="127.0.0.1:3013",handle="main",request="ping",le="+Inf"} 3`,
+		`tarantool_client_request_duration_seconds_sum{addr="127.0.0.1:3013",handle="main",request="ping"} 2`,
+		`tarantool_client_request_duration_seconds_count{addr="127.0.0.1:3013",handle="main",request="ping"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Metrics do not contain %q:\n%s", line, out)
+		}
+	}
+	if strings.Count(out, "# TYPE tarantool_client_connected ") != 1 {
+		t.Errorf("Metric family is written more than once:\n%s", out)
+	}
+	// sources are sorted by address
+	if strings.Index(out, `addr="127.0.0.1:3013"`) > strings.Index(out, `addr="127.0.0.1:3014"`) {
+		t.Errorf("Sources are not sorted:\n%s", out)
+	}
+}
+
+func TestCollectorEmpty(t *testing.T) {
+	collector := NewCollector()
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Unexpected metrics without connections:\n%s", rec.Body.String())
+	}
+}
+
+func TestCollectorUnregisterOnClose(t *testing.T) {
+	conn, err := tarantool.Connect("127.0.0.1:1", tarantool.Opts{
+		Reconnect:  time.Hour,
+		SkipSchema: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create connection: %s", err)
+	}
+	collector := NewCollector()
+	collector.Register(conn)
+	collector.Register(conn)
+	if sources := collector.collect(); len(sources) != 1 {
+		t.Fatalf("Unexpected sources of registered connection: %+v", sources)
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		collector.mutex.Lock()
+		n := len(collector.conns)
+		collector.mutex.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Closed connection is not unregistered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// closed connection is not registered
+	collector.Register(conn)
+	if len(collector.conns) != 0 {
+		t.Errorf("Closed connection is registered")
+	}
+}
+
+func TestCollectorSameLabels(t *testing.T) {
+	opts := tarantool.Opts{Reconnect: time.Hour, SkipSchema: true}
+	var conns []*tarantool.Connection
+	for i := 0; i < 2; i++ {
+		conn, err := tarantool.Connect("127.0.0.1:1", opts)
+		if err != nil {
+			t.Fatalf("Failed to create connection: %s", err)
+		}
+		defer conn.Close()
+		conn.Ping()
+		conns = append(conns, conn)
+	}
+	collector := NewCollector()
+	for _, conn := range conns {
+		collector.Register(conn)
+	}
+
+	// distinct connections with the same labels are summed
+	sources := collector.collect()
+	if len(sources) != 1 {
+		t.Fatalf("Unexpected sources: %+v", sources)
+	}
+	var errs uint64
+	for _, n := range sources[0].stats.Errors {
+		errs += n
+	}
+	if errs != 2 {
+		t.Errorf("Statistics of connections are not summed: %+v", sources[0].stats)
+	}
+}
@@ -18,7 +18,10 @@ box.cfg{
     box.schema.func.create('queue.tube.test_queue:delete')
     box.schema.func.create('queue.tube.test_queue:release')
     box.schema.func.create('queue.tube.test_queue:bury')
+    box.schema.func.create('queue.tube.test_queue:touch')
+    box.schema.func.create('queue.tube.test_queue:release_all')
     box.schema.func.create('queue.statistics')
+    box.schema.func.create('queue.state')
     box.schema.user.grant('test', 'create', 'space')
     box.schema.user.grant('test', 'write', 'space', '_schema')
     box.schema.user.grant('test', 'write', 'space', '_space')
@@ -8,6 +8,15 @@ const (
 	DELAYED = "~"
 )
 
+// States of queue (see Queue.State).
+const (
+	InitState    = "INIT"
+	StartupState = "STARTUP"
+	RunningState = "RUNNING"
+	EndingState  = "ENDING"
+	WaitingState = "WAITING"
+)
+
 type queueType string
 
 const (
@@ -1,13 +1,19 @@
 package queue
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/tarantool/go-tarantool"
 	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
 
+// ErrTubeNotFound is returned when configuration of not existing tube is
+// requested.
+var ErrTubeNotFound = errors.New("tube does not exist")
+
 // Queue is a handle to tarantool queue's tube
 type Queue interface {
 	// Exists checks tube for existence
@@ -32,8 +38,10 @@ type Queue interface {
 	Take() (*Task, error)
 	// TakeWithTimout takes 'ready' task from a tube and marks it as "in progress",
 	// or it is timeouted after "timeout" period.
-	// Note: if connection has a request Timeout, and conn.Timeout * 0.9 < timeout
-	// then timeout = conn.Timeout*0.9
+	// Note: if connection supports per-request timeouts (tarantool.Connection
+	// and tarantool.ScopedConnection do), request timeout is extended to
+	// cover "timeout". Otherwise, if connection has a request Timeout, and
+	// conn.Timeout * 0.9 < timeout then timeout = conn.Timeout*0.9
 	TakeTimeout(timeout time.Duration) (*Task, error)
 	// Take takes 'ready' task from a tube and marks it as 'in progress'
 	// Note: if connection has a request Timeout, then 0.9 * connection.Timeout is
@@ -42,8 +50,10 @@ type Queue interface {
 	TakeTyped(interface{}) (*Task, error)
 	// TakeWithTimout takes 'ready' task from a tube and marks it as "in progress",
 	// or it is timeouted after "timeout" period.
-	// Note: if connection has a request Timeout, and conn.Timeout * 0.9 < timeout
-	// then timeout = conn.Timeout*0.9
+	// Note: if connection supports per-request timeouts (tarantool.Connection
+	// and tarantool.ScopedConnection do), request timeout is extended to
+	// cover "timeout". Otherwise, if connection has a request Timeout, and
+	// conn.Timeout * 0.9 < timeout then timeout = conn.Timeout*0.9
 	// data will be unpacked to result
 	TakeTypedTimeout(timeout time.Duration, result interface{}) (*Task, error)
 	// Peek returns task by its id.
@@ -54,6 +64,14 @@ type Queue interface {
 	Delete(taskId uint64) error
 	// Statistic returns some statistic about queue.
 	Statistic() (interface{}, error)
+	// ReleaseAll returns all taken tasks of a tube back to the queue.
+	ReleaseAll() error
+	// State returns state of queue: InitState, StartupState, RunningState,
+	// EndingState or WaitingState.
+	State() (string, error)
+	// Cfg returns kind of a tube and its default task options.
+	// Note: it uses Eval, so user needs 'execute universe' privilege
+	Cfg() (Cfg, error)
 }
 
 type queue struct {
@@ -72,7 +90,10 @@ type cmd struct {
 	bury       string
 	kick       string
 	release    string
+	releaseAll string
+	touch      string
 	statistics string
+	state      string
 }
 
 type Cfg struct {
@@ -189,16 +210,13 @@ func (q *queue) Take() (*Task, error) {
 	if timeout > 0 {
 		params = (timeout * 9 / 10).Seconds()
 	}
-	return q.take(params)
+	return q.take(q.conn, params)
 }
 
 // The take request searches for a task in the queue. Waits until a task becomes ready or the timeout expires.
 func (q *queue) TakeTimeout(timeout time.Duration) (*Task, error) {
-	t := q.conn.ConfiguredTimeout() * 9 / 10
-	if t > 0 && timeout > t {
-		timeout = t
-	}
-	return q.take(timeout.Seconds())
+	conn, timeout := q.takeConn(timeout)
+	return q.take(conn, timeout.Seconds())
 }
 
 // The take request searches for a task in the queue.
@@ -208,24 +226,44 @@ func (q *queue) TakeTyped(result interface{}) (*Task, error) {
 	if timeout > 0 {
 		params = (timeout * 9 / 10).Seconds()
 	}
-	return q.take(params, result)
+	return q.take(q.conn, params, result)
 }
 
 // The take request searches for a task in the queue. Waits until a task becomes ready or the timeout expires.
 func (q *queue) TakeTypedTimeout(timeout time.Duration, result interface{}) (*Task, error) {
-	t := q.conn.ConfiguredTimeout() * 9 / 10
-	if t > 0 && timeout > t {
+	conn, timeout := q.takeConn(timeout)
+	return q.take(conn, timeout.Seconds(), result)
+}
+
+// timeoutConnector is a connector supporting per-request timeouts.
+type timeoutConnector interface {
+	WithTimeout(timeout time.Duration) *tarantool.ScopedConnection
+}
+
+// takeConn returns connector for take request waiting for timeout.
+// If connector supports per-request timeouts, request timeout is extended
+// by configured timeout, otherwise timeout is clamped to
+// 0.9 * ConfiguredTimeout.
+func (q *queue) takeConn(timeout time.Duration) (tarantool.Connector, time.Duration) {
+	configured := q.conn.ConfiguredTimeout()
+	if configured <= 0 {
+		return q.conn, timeout
+	}
+	if conn, ok := q.conn.(timeoutConnector); ok {
+		return conn.WithTimeout(timeout + configured), timeout
+	}
+	if t := configured * 9 / 10; timeout > t {
 		timeout = t
 	}
-	return q.take(timeout.Seconds(), result)
+	return q.conn, timeout
 }
 
-func (q *queue) take(params interface{}, result ...interface{}) (*Task, error) {
+func (q *queue) take(conn tarantool.Connector, params interface{}, result ...interface{}) (*Task, error) {
 	qd := queueData{q: q}
 	if len(result) > 0 {
 		qd.result = result[0]
 	}
-	if err := q.conn.CallTyped(q.cmds.take, []interface{}{params}, &qd); err != nil {
+	if err := conn.CallTyped(q.cmds.take, []interface{}{params}, &qd); err != nil {
 		return nil, err
 	}
 	return qd.task, nil
@@ -261,6 +299,11 @@ func (q *queue) _bury(taskId uint64) (string, error) {
 func (q *queue) _release(taskId uint64, cfg Opts) (string, error) {
 	return q.produce(q.cmds.release, taskId, cfg.toMap())
 }
+
+func (q *queue) _touch(taskId uint64, increment time.Duration) (string, error) {
+	return q.produce(q.cmds.touch, taskId, increment.Seconds())
+}
+
 func (q *queue) produce(cmd string, params ...interface{}) (string, error) {
 	qd := queueData{q: q}
 	if err := q.conn.CallTyped(cmd, params, &qd); err != nil || qd.task == nil {
@@ -302,6 +345,62 @@ func (q *queue) Statistic() (interface{}, error) {
 	return nil, nil
 }
 
+// Return all taken tasks back to the queue.
+func (q *queue) ReleaseAll() error {
+	_, err := q.conn.Call(q.cmds.releaseAll, []interface{}{})
+	return err
+}
+
+// Return the state of the queue.
+func (q *queue) State() (string, error) {
+	resp, err := q.conn.Call17(q.cmds.state, []interface{}{})
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Data) != 0 {
+		if state, ok := resp.Data[0].(string); ok {
+			return state, nil
+		}
+	}
+
+	return "", fmt.Errorf("unexpected queue state: %v", resp.Data)
+}
+
+// Return the kind and default task options of a tube.
+func (q *queue) Cfg() (Cfg, error) {
+	cmd := `local name = ... ; local tube = queue.tube[name]
+if tube == nil then return nil end
+local opts = tube.opts or {}
+return {tube.type, opts.temporary == true, opts.ttl or 0, opts.ttr or 0,
+        opts.pri or 0, opts.delay or 0}`
+	var resp []cfgData
+	if err := q.conn.EvalTyped(cmd, []interface{}{q.name}, &resp); err != nil {
+		return Cfg{}, err
+	}
+	if len(resp) == 0 || resp[0].cfg == nil {
+		return Cfg{}, ErrTubeNotFound
+	}
+	return *resp[0].cfg, nil
+}
+
+// Tubes returns names of all tubes.
+// Note: it uses Eval, so user needs 'execute universe' privilege
+func Tubes(conn tarantool.Connector) ([]string, error) {
+	cmd := `local names = {}
+for name in pairs(queue.tube) do table.insert(names, name) end
+table.sort(names)
+return names`
+	var resp [][]string
+	if err := conn.EvalTyped(cmd, []interface{}{}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, nil
+	}
+	return resp[0], nil
+}
+
 func makeCmd(q *queue) {
 	q.cmds = cmd{
 		put:        "queue.tube." + q.name + ":put",
@@ -313,7 +412,10 @@ func makeCmd(q *queue) {
 		bury:       "queue.tube." + q.name + ":bury",
 		kick:       "queue.tube." + q.name + ":kick",
 		release:    "queue.tube." + q.name + ":release",
+		releaseAll: "queue.tube." + q.name + ":release_all",
+		touch:      "queue.tube." + q.name + ":touch",
 		statistics: "queue.statistics",
+		state:      "queue.state",
 	}
 }
 
@@ -340,3 +442,58 @@ func (qd *queueData) DecodeMsgpack(d *msgpack.Decoder) error {
 	d.Decode(&qd.task)
 	return nil
 }
+
+// cfgData decodes configuration of a tube:
+// [type, temporary, ttl, ttr, pri, delay] or nil.
+type cfgData struct {
+	cfg *Cfg
+}
+
+func (cd *cfgData) DecodeMsgpack(d *msgpack.Decoder) error {
+	var err error
+	var l int
+	if l, err = d.DecodeSliceLen(); err != nil {
+		return err
+	}
+	if l == -1 {
+		return nil
+	}
+	if l < 6 {
+		return fmt.Errorf("array len doesn't match for tube cfg: %d", l)
+	}
+
+	cfg := &Cfg{}
+	var kind string
+	if kind, err = d.DecodeString(); err != nil {
+		return err
+	}
+	cfg.Kind = queueType(kind)
+	if cfg.Temporary, err = d.DecodeBool(); err != nil {
+		return err
+	}
+	for _, dur := range []*time.Duration{&cfg.Ttl, &cfg.Ttr} {
+		if *dur, err = decodeDuration(d); err != nil {
+			return err
+		}
+	}
+	if cfg.Pri, err = d.DecodeInt(); err != nil {
+		return err
+	}
+	if cfg.Delay, err = decodeDuration(d); err != nil {
+		return err
+	}
+	cd.cfg = cfg
+	return nil
+}
+
+func decodeDuration(d *msgpack.Decoder) (time.Duration, error) {
+	seconds, err := d.DecodeFloat64()
+	if err != nil {
+		return 0, err
+	}
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		// infinite ttl/ttr of queue
+		return time.Duration(math.MaxInt64), nil
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -822,6 +822,212 @@ func TestUtube_Put(t *testing.T) {
 	}
 }
 
+func TestFifoQueue_TakeTimeoutLongerThanConnTimeout(t *testing.T) {
+	conn, err := Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	name := "test_queue_long_take"
+	q := queue.New(conn, name)
+	if err = q.Create(queue.Cfg{Temporary: true, Kind: queue.FIFO}); err != nil {
+		t.Fatalf("Failed to create queue: %s", err.Error())
+	}
+	defer func() {
+		//Drop
+		err := q.Drop()
+		if err != nil {
+			t.Errorf("Failed drop queue: %s", err.Error())
+		}
+	}()
+
+	// take waits longer than opts.Timeout of connection
+	start := time.Now()
+	task, err := q.TakeTimeout(opts.Timeout + time.Second)
+	if err != nil {
+		t.Fatalf("Failed to take from empty queue: %s", err.Error())
+	}
+	if task != nil {
+		t.Errorf("Task is taken from empty queue")
+	}
+	if elapsed := time.Since(start); elapsed < opts.Timeout+900*time.Millisecond {
+		t.Errorf("Take timeout is clamped: %s", elapsed)
+	}
+}
+
+func TestTtlQueue_Touch(t *testing.T) {
+	conn, err := Connect(server, opts)
+	if err != nil {
+		t.Errorf("Failed to connect: %s", err.Error())
+		return
+	}
+	defer conn.Close()
+
+	name := "test_queue"
+	cfg := queue.Cfg{
+		Temporary: true,
+		Kind:      queue.FIFO_TTL,
+		Opts:      queue.Opts{Ttl: 2 * time.Second, Ttr: 1 * time.Second},
+	}
+	q := queue.New(conn, name)
+	if err = q.Create(cfg); err != nil {
+		t.Errorf("Failed to create queue: %s", err.Error())
+		return
+	}
+
+	defer func() {
+		//Drop
+		err := q.Drop()
+		if err != nil {
+			t.Errorf("Failed drop queue: %s", err.Error())
+		}
+	}()
+
+	if _, err = q.Put("touch_data"); err != nil {
+		t.Errorf("Failed put to queue: %s", err.Error())
+		return
+	}
+
+	task, err := q.Take()
+	if err != nil {
+		t.Errorf("Failed take from queue: %s", err.Error())
+		return
+	} else if task == nil {
+		t.Errorf("Task is nil after take")
+		return
+	}
+
+	//Touch
+	if err = task.Touch(3 * time.Second); err != nil {
+		t.Errorf("Failed touch task: %s", err.Error())
+		return
+	}
+	if !task.IsTaken() {
+		t.Errorf("Task status after touch is not taken. Status = %s", task.Status())
+	}
+
+	// task is neither returned by ttr nor deleted by ttl after touch
+	time.Sleep(2 * time.Second)
+
+	if err = task.Ack(); err != nil {
+		t.Errorf("Failed ack touched task: %s", err.Error())
+	} else if !task.IsDone() {
+		t.Errorf("Task status after ack is not done. Status = %s", task.Status())
+	}
+}
+
+func TestFifoQueue_ReleaseAll(t *testing.T) {
+	conn, err := Connect(server, opts)
+	if err != nil {
+		t.Errorf("Failed to connect: %s", err.Error())
+		return
+	}
+	defer conn.Close()
+
+	name := "test_queue"
+	q := queue.New(conn, name)
+	if err = q.Create(queue.Cfg{Temporary: true, Kind: queue.FIFO}); err != nil {
+		t.Errorf("Failed to create queue: %s", err.Error())
+		return
+	}
+
+	defer func() {
+		//Drop
+		err := q.Drop()
+		if err != nil {
+			t.Errorf("Failed drop queue: %s", err.Error())
+		}
+	}()
+
+	var ids []uint64
+	for i := 0; i < 2; i++ {
+		if _, err = q.Put(fmt.Sprintf("release_all_%d", i)); err != nil {
+			t.Errorf("Failed put to queue: %s", err.Error())
+			return
+		}
+		task, err := q.Take()
+		if err != nil || task == nil {
+			t.Errorf("Failed take from queue: %v", err)
+			return
+		}
+		ids = append(ids, task.Id())
+	}
+
+	//ReleaseAll
+	if err = q.ReleaseAll(); err != nil {
+		t.Errorf("Failed release all tasks: %s", err.Error())
+		return
+	}
+
+	for _, id := range ids {
+		task, err := q.Peek(id)
+		if err != nil {
+			t.Errorf("Failed peek task %d: %s", id, err.Error())
+		} else if !task.IsReady() {
+			t.Errorf("Task %d status after release all is not ready. Status = %s", id, task.Status())
+		}
+	}
+}
+
+func TestQueue_State_Tubes_Cfg(t *testing.T) {
+	conn, err := Connect(server, opts)
+	if err != nil {
+		t.Errorf("Failed to connect: %s", err.Error())
+		return
+	}
+	defer conn.Close()
+
+	name := "test_queue"
+	cfg := queue.Cfg{
+		Temporary: true,
+		Kind:      queue.FIFO_TTL,
+		Opts:      queue.Opts{Ttl: 5 * time.Second, Ttr: 500 * time.Millisecond, Pri: 1},
+	}
+	q := queue.New(conn, name)
+	if err = q.Create(cfg); err != nil {
+		t.Errorf("Failed to create queue: %s", err.Error())
+		return
+	}
+
+	defer func() {
+		//Drop
+		err := q.Drop()
+		if err != nil {
+			t.Errorf("Failed drop queue: %s", err.Error())
+		}
+	}()
+
+	//State
+	state, err := q.State()
+	if err != nil {
+		t.Errorf("Failed get queue state: %s", err.Error())
+	} else if state != queue.RunningState {
+		t.Errorf("Unexpected queue state: %s", state)
+	}
+
+	//Tubes
+	tubes, err := queue.Tubes(conn)
+	if err != nil {
+		t.Errorf("Failed list tubes: %s", err.Error())
+	} else if len(tubes) != 1 || tubes[0] != name {
+		t.Errorf("Unexpected tubes: %v", tubes)
+	}
+
+	//Cfg
+	tubeCfg, err := q.Cfg()
+	if err != nil {
+		t.Errorf("Failed get tube cfg: %s", err.Error())
+	} else if tubeCfg.Kind != cfg.Kind || !tubeCfg.Temporary || tubeCfg.Ttl != cfg.Ttl ||
+		tubeCfg.Ttr != cfg.Ttr || tubeCfg.Pri != cfg.Pri {
+		t.Errorf("Unexpected tube cfg: %+v, expected %+v", tubeCfg, cfg)
+	}
+
+	if _, err = queue.New(conn, "not_existing_queue").Cfg(); err != queue.ErrTubeNotFound {
+		t.Errorf("Unexpected error for not existing tube: %v", err)
+	}
+}
+
 // runTestMain is a body of TestMain function
 // (see https://pkg.go.dev/testing#hdr-Main).
 // Using defer + os.Exit is not works so TestMain body
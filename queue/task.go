@@ -2,6 +2,7 @@ package queue
 
 import (
 	"fmt"
+	"time"
 
 	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
@@ -86,6 +87,12 @@ func (t *Task) ReleaseCfg(cfg Opts) error {
 	return t.accept(t.q._release(t.id, cfg))
 }
 
+// Touch increases ttl and ttr of a taken task by increment, so worker could
+// keep a long running task. Tube should support ttl (FIFO_TTL or UTUBE_TTL).
+func (t *Task) Touch(increment time.Duration) error {
+	return t.accept(t.q._touch(t.id, increment))
+}
+
 func (t *Task) accept(newStatus string, err error) error {
 	if err == nil {
 		t.status = newStatus
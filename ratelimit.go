@@ -0,0 +1,153 @@
+package tarantool
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// RequestRate configures token bucket limiting throughput of requests.
+type RequestRate struct {
+	// PerSecond is a number of requests allowed per second.
+	PerSecond float64
+	// Burst is a maximal number of requests which could be sent at once
+	// after a period of inactivity. By default it is PerSecond rounded up.
+	Burst uint
+}
+
+// tokenBucket implements token bucket rate limiter.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate RequestRate) (*tokenBucket, error) {
+	if rate.PerSecond <= 0 {
+		return nil, errors.New("RequestRate.PerSecond should be positive")
+	}
+	burst := float64(rate.Burst)
+	if burst == 0 {
+		burst = math.Ceil(rate.PerSecond)
+	}
+	return &tokenBucket{
+		rate:   rate.PerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}, nil
+}
+
+// reserve takes a token from bucket and returns time to wait until token is
+// available. If wait would be longer than maxWait (and maxWait is not
+// negative), token is not taken and false is returned.
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		if maxWait >= 0 && wait > maxWait {
+			return 0, false
+		}
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel returns reserved token to bucket.
+func (b *tokenBucket) cancel() {
+	b.mutex.Lock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.mutex.Unlock()
+}
+
+// throttler holds token buckets of connection.
+type throttler struct {
+	all    *tokenBucket
+	byCode map[int32]*tokenBucket
+}
+
+func newThrottler(opts Opts) (*throttler, error) {
+	if opts.RequestRate.PerSecond == 0 && len(opts.RequestRateByCode) == 0 {
+		return nil, nil
+	}
+	t := &throttler{byCode: make(map[int32]*tokenBucket)}
+	var err error
+	if opts.RequestRate.PerSecond != 0 {
+		if t.all, err = newTokenBucket(opts.RequestRate); err != nil {
+			return nil, err
+		}
+	}
+	for code, rate := range opts.RequestRateByCode {
+		if t.byCode[code], err = newTokenBucket(rate); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// reserve takes tokens for request from all matching buckets and returns
+// time to wait until request could be sent. maxWait is zero for RLimitDrop
+// action.
+func (t *throttler) reserve(requestCode int32, maxWait time.Duration) (time.Duration, bool) {
+	now := time.Now()
+	var wait time.Duration
+	if t.all != nil {
+		w, ok := t.all.reserve(now, maxWait)
+		if !ok {
+			return 0, false
+		}
+		wait = w
+	}
+	if b := t.byCode[requestCode]; b != nil {
+		w, ok := b.reserve(now, maxWait)
+		if !ok {
+			if t.all != nil {
+				t.all.cancel()
+			}
+			return 0, false
+		}
+		if w > wait {
+			wait = w
+		}
+	}
+	return wait, true
+}
+
+// throttle delays or rejects request according to request rate limits.
+// With RLimitWait request waits until its deadline at most. Internal
+// requests of connection are not limited.
+func (conn *Connection) throttle(scope *requestScope, requestCode int32, deadline time.Time) error {
+	if conn.throttler == nil || (scope != nil && scope.internal) {
+		return nil
+	}
+	var maxWait time.Duration
+	if conn.opts.RLimitAction == RLimitWait {
+		maxWait = -1
+		if !deadline.IsZero() {
+			maxWait = time.Until(deadline)
+		}
+	}
+	wait, ok := conn.throttler.reserve(requestCode, maxWait)
+	if !ok {
+		return ClientError{ErrRateLimited, "Request rate is limited on client"}
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package tarantool_test
+
+import (
+	"testing"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestTokenBucketCancel(t *testing.T) {
+	if tokens := TokensAfterCancel(RequestRate{PerSecond: 1, Burst: 2}); tokens != 2 {
+		t.Errorf("Cancel overflows bucket: %v tokens, burst is 2", tokens)
+	}
+}
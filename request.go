@@ -2,6 +2,7 @@ package tarantool
 
 import (
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"gopkg.in/vmihailenco/msgpack.v2"
@@ -9,9 +10,15 @@ import (
 
 // Future is a handle for asynchronous request
 type Future struct {
+	// sent is accessed atomically, it is first to be 64-bit aligned.
+	sent        int64
 	requestId   uint32
 	requestCode int32
 	timeout     time.Duration
+	// deadlinePos is a position in deadlines heap of shard plus one, or 0
+	// if request is not in heap.
+	deadlinePos int
+	icpt        *intercepted
 	resp        *Response
 	err         error
 	ready       chan struct{}
@@ -20,8 +27,17 @@ type Future struct {
 
 // Ping sends empty request to Tarantool to check connection.
 func (conn *Connection) Ping() (resp *Response, err error) {
-	future := conn.newFuture(PingRequest)
-	return future.send(conn, func(enc *msgpack.Encoder) error { enc.EncodeMapLen(0); return nil }).Get()
+	return conn.pingAsync(nil).Get()
+}
+
+func (conn *Connection) pingAsync(scope *requestScope) *Future {
+	if conn.shouldRetry(scope, PingRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.pingAsync(scope)
+		})
+	}
+	future := conn.newFuture(scope, PingRequest)
+	return future.send(conn, func(enc *msgpack.Encoder) error { enc.EncodeMapLen(0); return nil })
 }
 
 func (req *Future) fillSearch(enc *msgpack.Encoder, spaceNo, indexNo uint32, key interface{}) error {
@@ -214,11 +230,22 @@ func (conn *Connection) EvalTyped(expr string, args interface{}, result interfac
 
 // SelectAsync sends select request to tarantool and returns Future.
 func (conn *Connection) SelectAsync(space, index interface{}, offset, limit, iterator uint32, key interface{}) *Future {
-	future := conn.newFuture(SelectRequest)
-	spaceNo, indexNo, err := conn.Schema.resolveSpaceIndex(space, index)
+	return conn.selectAsync(nil, space, index, offset, limit, iterator, key)
+}
+
+func (conn *Connection) selectAsync(scope *requestScope, space, index interface{}, offset, limit, iterator uint32, key interface{}) *Future {
+	if conn.shouldRetry(scope, SelectRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.selectAsync(scope, space, index, offset, limit, iterator, key)
+		})
+	}
+	future := conn.newFuture(scope, SelectRequest)
+	schema := conn.GetSchema()
+	spaceNo, indexNo, err := schema.resolveSpaceIndex(space, index)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, index, spaceNo, indexNo)
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(6)
 		future.fillIterator(enc, offset, limit, iterator)
@@ -229,11 +256,22 @@ func (conn *Connection) SelectAsync(space, index interface{}, offset, limit, ite
 // InsertAsync sends insert action to tarantool and returns Future.
 // Tarantool will reject Insert when tuple with same primary key exists.
 func (conn *Connection) InsertAsync(space interface{}, tuple interface{}) *Future {
-	future := conn.newFuture(InsertRequest)
-	spaceNo, _, err := conn.Schema.resolveSpaceIndex(space, nil)
+	return conn.insertAsync(nil, space, tuple)
+}
+
+func (conn *Connection) insertAsync(scope *requestScope, space interface{}, tuple interface{}) *Future {
+	if conn.shouldRetry(scope, InsertRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.insertAsync(scope, space, tuple)
+		})
+	}
+	future := conn.newFuture(scope, InsertRequest)
+	schema := conn.GetSchema()
+	spaceNo, _, err := schema.resolveSpaceIndex(space, nil)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, nil, spaceNo, 0)
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(2)
 		return future.fillInsert(enc, spaceNo, tuple)
@@ -243,11 +281,22 @@ func (conn *Connection) InsertAsync(space interface{}, tuple interface{}) *Futur
 // ReplaceAsync sends "insert or replace" action to tarantool and returns Future.
 // If tuple with same primary key exists, it will be replaced.
 func (conn *Connection) ReplaceAsync(space interface{}, tuple interface{}) *Future {
-	future := conn.newFuture(ReplaceRequest)
-	spaceNo, _, err := conn.Schema.resolveSpaceIndex(space, nil)
+	return conn.replaceAsync(nil, space, tuple)
+}
+
+func (conn *Connection) replaceAsync(scope *requestScope, space interface{}, tuple interface{}) *Future {
+	if conn.shouldRetry(scope, ReplaceRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.replaceAsync(scope, space, tuple)
+		})
+	}
+	future := conn.newFuture(scope, ReplaceRequest)
+	schema := conn.GetSchema()
+	spaceNo, _, err := schema.resolveSpaceIndex(space, nil)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, nil, spaceNo, 0)
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(2)
 		return future.fillInsert(enc, spaceNo, tuple)
@@ -257,11 +306,22 @@ func (conn *Connection) ReplaceAsync(space interface{}, tuple interface{}) *Futu
 // DeleteAsync sends deletion action to tarantool and returns Future.
 // Future's result will contain array with deleted tuple.
 func (conn *Connection) DeleteAsync(space, index interface{}, key interface{}) *Future {
-	future := conn.newFuture(DeleteRequest)
-	spaceNo, indexNo, err := conn.Schema.resolveSpaceIndex(space, index)
+	return conn.deleteAsync(nil, space, index, key)
+}
+
+func (conn *Connection) deleteAsync(scope *requestScope, space, index interface{}, key interface{}) *Future {
+	if conn.shouldRetry(scope, DeleteRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.deleteAsync(scope, space, index, key)
+		})
+	}
+	future := conn.newFuture(scope, DeleteRequest)
+	schema := conn.GetSchema()
+	spaceNo, indexNo, err := schema.resolveSpaceIndex(space, index)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, index, spaceNo, indexNo)
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(3)
 		return future.fillSearch(enc, spaceNo, indexNo, key)
@@ -271,11 +331,25 @@ func (conn *Connection) DeleteAsync(space, index interface{}, key interface{}) *
 // Update sends deletion of a tuple by key and returns Future.
 // Future's result will contain array with updated tuple.
 func (conn *Connection) UpdateAsync(space, index interface{}, key, ops interface{}) *Future {
-	future := conn.newFuture(UpdateRequest)
-	spaceNo, indexNo, err := conn.Schema.resolveSpaceIndex(space, index)
+	return conn.updateAsync(nil, space, index, key, ops)
+}
+
+func (conn *Connection) updateAsync(scope *requestScope, space, index interface{}, key, ops interface{}) *Future {
+	if conn.shouldRetry(scope, UpdateRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.updateAsync(scope, space, index, key, ops)
+		})
+	}
+	future := conn.newFuture(scope, UpdateRequest)
+	schema := conn.GetSchema()
+	spaceNo, indexNo, err := schema.resolveSpaceIndex(space, index)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, index, spaceNo, indexNo)
+	if err = schema.validateOps(spaceNo, ops); err != nil {
+		return future.fail(conn, err)
+	}
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(4)
 		if err := future.fillSearch(enc, spaceNo, indexNo, key); err != nil {
@@ -289,11 +363,25 @@ func (conn *Connection) UpdateAsync(space, index interface{}, key, ops interface
 // UpsertAsync sends "update or insert" action to tarantool and returns Future.
 // Future's sesult will not contain any tuple.
 func (conn *Connection) UpsertAsync(space interface{}, tuple interface{}, ops interface{}) *Future {
-	future := conn.newFuture(UpsertRequest)
-	spaceNo, _, err := conn.Schema.resolveSpaceIndex(space, nil)
+	return conn.upsertAsync(nil, space, tuple, ops)
+}
+
+func (conn *Connection) upsertAsync(scope *requestScope, space interface{}, tuple interface{}, ops interface{}) *Future {
+	if conn.shouldRetry(scope, UpsertRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.upsertAsync(scope, space, tuple, ops)
+		})
+	}
+	future := conn.newFuture(scope, UpsertRequest)
+	schema := conn.GetSchema()
+	spaceNo, _, err := schema.resolveSpaceIndex(space, nil)
 	if err != nil {
 		return future.fail(conn, err)
 	}
+	future.describeSpace(schema, space, nil, spaceNo, 0)
+	if err = schema.validateOps(spaceNo, ops); err != nil {
+		return future.fail(conn, err)
+	}
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(3)
 		enc.EncodeUint64(KeySpaceNo)
@@ -310,7 +398,17 @@ func (conn *Connection) UpsertAsync(space interface{}, tuple interface{}, ops in
 // CallAsync sends a call to registered tarantool function and returns Future.
 // It uses request code for tarantool 1.6, so future's result is always array of arrays
 func (conn *Connection) CallAsync(functionName string, args interface{}) *Future {
-	future := conn.newFuture(CallRequest)
+	return conn.callAsync(nil, functionName, args)
+}
+
+func (conn *Connection) callAsync(scope *requestScope, functionName string, args interface{}) *Future {
+	if conn.shouldRetry(scope, CallRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.callAsync(scope, functionName, args)
+		})
+	}
+	future := conn.newFuture(scope, CallRequest)
+	future.describeCall(functionName, "")
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(2)
 		enc.EncodeUint64(KeyFunctionName)
@@ -324,7 +422,17 @@ func (conn *Connection) CallAsync(functionName string, args interface{}) *Future
 // It uses request code for tarantool 1.7, so future's result will not be converted
 // (though, keep in mind, result is always array)
 func (conn *Connection) Call17Async(functionName string, args interface{}) *Future {
-	future := conn.newFuture(Call17Request)
+	return conn.call17Async(nil, functionName, args)
+}
+
+func (conn *Connection) call17Async(scope *requestScope, functionName string, args interface{}) *Future {
+	if conn.shouldRetry(scope, Call17Request) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.call17Async(scope, functionName, args)
+		})
+	}
+	future := conn.newFuture(scope, Call17Request)
+	future.describeCall(functionName, "")
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(2)
 		enc.EncodeUint64(KeyFunctionName)
@@ -336,7 +444,17 @@ func (conn *Connection) Call17Async(functionName string, args interface{}) *Futu
 
 // EvalAsync sends a lua expression for evaluation and returns Future.
 func (conn *Connection) EvalAsync(expr string, args interface{}) *Future {
-	future := conn.newFuture(EvalRequest)
+	return conn.evalAsync(nil, expr, args)
+}
+
+func (conn *Connection) evalAsync(scope *requestScope, expr string, args interface{}) *Future {
+	if conn.shouldRetry(scope, EvalRequest) {
+		return conn.retry(scope, func(scope *requestScope) *Future {
+			return conn.evalAsync(scope, expr, args)
+		})
+	}
+	future := conn.newFuture(scope, EvalRequest)
+	future.describeCall("", expr)
 	return future.send(conn, func(enc *msgpack.Encoder) error {
 		enc.EncodeMapLen(2)
 		enc.EncodeUint64(KeyExpression)
@@ -383,7 +501,20 @@ func (fut *Future) send(conn *Connection, body func(*msgpack.Encoder) error) *Fu
 	return fut
 }
 
+// markReady marks future as completed and passes it to interceptors.
+// It should not be called under shard locks.
 func (fut *Future) markReady(conn *Connection) {
+	if fut.icpt != nil {
+		conn.complete(fut)
+	}
+	fut.markReadyLocked(conn)
+}
+
+// markReadyLocked marks future as completed without calling interceptors,
+// so it could be called under shard locks. conn.complete should be called
+// for future after locks are released.
+func (fut *Future) markReadyLocked(conn *Connection) {
+	conn.stats.requestDone(fut)
 	close(fut.ready)
 	if conn.rlimit != nil {
 		<-conn.rlimit
@@ -398,6 +529,49 @@ func (fut *Future) fail(conn *Connection, err error) *Future {
 	return fut
 }
 
+// NewErrorFuture returns Future completed with err. It is useful for
+// implementations of Connector, which could fail before request is sent.
+func NewErrorFuture(err error) *Future {
+	return &Future{err: err}
+}
+
+// ChainFuture returns Future completed with result of fut or of futures
+// made by next. When future is completed, next is called with its error
+// (including error returned by Tarantool) and returns following future or
+// nil, if result is final. It is useful for implementations of Connector,
+// which resend requests.
+func ChainFuture(fut *Future, next func(err error) *Future) *Future {
+	result := &Future{requestCode: fut.requestCode, ready: make(chan struct{})}
+	go func() {
+		var err error
+		for {
+			fut.wait()
+			err = fut.err
+			if err == nil && fut.resp.Code != OkCode {
+				// body of error response is small, so it is decoded
+				// here to classify error
+				err = fut.resp.decodeBody()
+			}
+			following := next(err)
+			if following == nil {
+				break
+			}
+			fut = following
+		}
+		result.requestId = fut.requestId
+		result.resp = fut.resp
+		result.err = err
+		close(result.ready)
+	}()
+	return result
+}
+
+// sentAt returns time of request sending since epoch, or 0 if request is
+// not sent.
+func (fut *Future) sentAt() time.Duration {
+	return time.Duration(atomic.LoadInt64(&fut.sent))
+}
+
 func (fut *Future) wait() {
 	if fut.ready == nil {
 		return
@@ -0,0 +1,107 @@
+package tarantool
+
+import (
+	"io"
+	"net"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of idempotent requests: Select
+// (and so GetTyped), Ping and requests made with Idempotent view of
+// connection. It is specified in Opts.Retry.
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts including first one.
+	// Retries are disabled if it is less than 2.
+	MaxAttempts uint
+	// Backoff is a policy of pauses between attempts. By default pause is
+	// 100ms.
+	Backoff Backoff
+	// Retryable reports if request failed with err could be retried.
+	// DefaultRetryable is used by default.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryable reports if request failed with err could be retried.
+// It returns true for temporary ClientError (see ClientError.Temporary),
+// network errors, which break connection, and server errors ErrReadonly,
+// ErrTransactionConflict and ErrNoConnection.
+func DefaultRetryable(err error) bool {
+	switch e := err.(type) {
+	case ClientError:
+		return e.Temporary()
+	case Error:
+		switch e.Code {
+		case ErrReadonly, ErrTransactionConflict, ErrNoConnection:
+			return true
+		}
+		return false
+	case net.Error:
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+func (p RetryPolicy) nextDelay(attempt uint, prev time.Duration) time.Duration {
+	if p.Backoff == nil {
+		return 100 * time.Millisecond
+	}
+	return p.Backoff.NextDelay(attempt, prev)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable == nil {
+		return DefaultRetryable(err)
+	}
+	return p.Retryable(err)
+}
+
+// Idempotent returns view of connection, which requests are retried
+// according to Opts.Retry as idempotent ones.
+func (conn *Connection) Idempotent() *ScopedConnection {
+	return &ScopedConnection{conn: conn, scope: requestScope{idempotent: true}}
+}
+
+// Idempotent returns copy of view, which requests are retried according to
+// Opts.Retry as idempotent ones.
+func (s *ScopedConnection) Idempotent() *ScopedConnection {
+	scoped := *s
+	scoped.scope.idempotent = true
+	return &scoped
+}
+
+// shouldRetry reports if request should be made with retries.
+func (conn *Connection) shouldRetry(scope *requestScope, requestCode int32) bool {
+	if conn.opts.Retry.MaxAttempts < 2 {
+		return false
+	}
+	if scope != nil && scope.noRetry {
+		return false
+	}
+	return requestCode == SelectRequest || requestCode == PingRequest ||
+		scope != nil && scope.idempotent
+}
+
+// retry makes request with do and repeats it while it fails with retryable
+// error. Returned future is ready when last attempt is completed.
+func (conn *Connection) retry(scope *requestScope, do func(*requestScope) *Future) *Future {
+	attemptScope := requestScope{}
+	if scope != nil {
+		attemptScope = *scope
+	}
+	attemptScope.noRetry = true
+	policy := conn.opts.Retry
+
+	attempt := uint(1)
+	var delay time.Duration
+	return ChainFuture(do(&attemptScope), func(err error) *Future {
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return nil
+		}
+		delay = policy.nextDelay(attempt-1, delay)
+		if !attemptScope.sleep(delay) {
+			return nil
+		}
+		attempt++
+		return do(&attemptScope)
+	})
+}
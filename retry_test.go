@@ -0,0 +1,54 @@
+package tarantool_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	. "github.com/tarantool/go-tarantool"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{ClientError{ErrConnectionNotReady, "not ready"}, true},
+		{ClientError{ErrTimeouted, "timeout"}, true},
+		{ClientError{ErrConnectionClosed, "closed"}, false},
+		{Error{ErrReadonly, "read only"}, true},
+		{Error{ErrTransactionConflict, "conflict"}, true},
+		{Error{ErrTupleFound, "duplicate"}, false},
+		{&net.OpError{Op: "read", Err: errors.New("connection reset")}, true},
+		{io.EOF, true},
+		{errors.New("msgpack: encoding error"), false},
+	}
+	for _, c := range cases {
+		if r := DefaultRetryable(c.err); r != c.retryable {
+			t.Errorf("DefaultRetryable(%v) = %v, expected %v", c.err, r, c.retryable)
+		}
+	}
+}
+
+func TestChainFuture(t *testing.T) {
+	errs := []error{
+		ClientError{ErrConnectionNotReady, "not ready"},
+		Error{ErrReadonly, "read only"},
+		Error{ErrTupleFound, "duplicate"},
+	}
+	var seen []error
+	fut := ChainFuture(NewErrorFuture(errs[0]), func(err error) *Future {
+		seen = append(seen, err)
+		if len(seen) == len(errs) {
+			return nil
+		}
+		return NewErrorFuture(errs[len(seen)])
+	})
+	if _, err := fut.Get(); err != errs[2] {
+		t.Errorf("Unexpected error of chained future: %v", err)
+	}
+	if len(seen) != len(errs) {
+		t.Errorf("Unexpected errors passed to next: %v", seen)
+	}
+}
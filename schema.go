@@ -2,15 +2,29 @@ package tarantool
 
 import (
 	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+	"gopkg.in/vmihailenco/msgpack.v2/codes"
 )
 
-// Schema contains information about spaces and indexes.
+// Schema contains information about spaces, indexes, sequences and functions.
 type Schema struct {
 	Version uint
 	// Spaces is map from space names to spaces
 	Spaces map[string]*Space
 	// SpacesById is map from space numbers to spaces
 	SpacesById map[uint32]*Space
+	// Sequences is map from sequence names to sequences
+	Sequences map[string]*Sequence
+	// SequencesById is map from sequence numbers to sequences
+	SequencesById map[uint32]*Sequence
+	// Functions is map from function names to functions
+	Functions map[string]*Function
+	// FunctionsById is map from function numbers to functions
+	FunctionsById map[uint32]*Function
 }
 
 // Space contains information about tarantool space
@@ -19,6 +33,8 @@ type Space struct {
 	Name      string
 	Engine    string
 	Temporary bool // Is this space temporaray?
+	IsLocal   bool // Is this space replica local?
+	IsSync    bool // Are transactions on this space synchronous?
 	// Field configuration is not mandatory and not checked by tarantool.
 	FieldsCount uint32
 	Fields      map[string]*Field
@@ -29,10 +45,15 @@ type Space struct {
 	IndexesById map[uint32]*Index
 }
 
+// Field contains information about field of space format
 type Field struct {
-	Id   uint32
-	Name string
-	Type string
+	Id         uint32
+	Name       string
+	Type       string
+	IsNullable bool
+	// Collation is a name of collation for string field,
+	// it is empty if collation is not set.
+	Collation string
 }
 
 // Index contains information about index
@@ -41,139 +62,782 @@ type Index struct {
 	Name   string
 	Type   string
 	Unique bool
+	// Hint tells if TREE index uses hints (tarantool 2.6.1+). It is false
+	// for older tarantool without hints support.
+	Hint   bool
 	Fields []*IndexField
+	// Opts contains all index options as they are stored in _vindex,
+	// including ones not parsed to other Index fields.
+	Opts map[string]interface{}
 }
 
+// IndexField contains information about index part
 type IndexField struct {
-	Id   uint32
-	Type string
+	Id         uint32
+	Type       string
+	IsNullable bool
+	// Collation is a name of collation for string part,
+	// it is empty if collation is not set.
+	Collation string
+	// Path is JSON path inside of field for multikey and JSON indexes
+	// (tarantool 2.1+), it is empty for plain fields.
+	Path string
+}
+
+// Sequence contains information about sequence (tarantool 1.7+)
+type Sequence struct {
+	Id    uint32
+	Name  string
+	Step  int64
+	Min   int64
+	Max   int64
+	Start int64
+	Cache int64
+	Cycle bool
+}
+
+// Function contains information about function registered with
+// box.schema.func.create.
+// Fields except Id, Name, SetUID and Language are filled by tarantool 2.2+.
+type Function struct {
+	Id              uint32
+	Name            string
+	SetUID          bool
+	Language        string
+	Body            string
+	RoutineType     string
+	Params          []string
+	Returns         string
+	IsDeterministic bool
+	IsSandboxed     bool
+	Exports         []string
 }
 
 const (
-	maxSchemas = 10000
-	spaceSpId  = 280
-	vspaceSpId = 281
-	indexSpId  = 288
-	vindexSpId = 289
+	schemaReloadAttempts = 3
+	schemaReloadDelay    = time.Second
 )
 
-func (conn *Connection) loadSchema() (err error) {
-	var resp *Response
+const (
+	maxSchemas     = 10000
+	spaceSpId      = 280
+	vspaceSpId     = 281
+	sequenceSpId   = 284
+	vsequenceSpId  = 286
+	indexSpId      = 288
+	vindexSpId     = 289
+	collationSpId  = 276
+	vcollationSpId = 277
+	funcSpId       = 296
+	vfuncSpId      = 297
+)
 
+func (conn *Connection) loadSchema() (err error) {
+	start := time.Now()
 	schema := new(Schema)
+	defer func() {
+		if err != nil {
+			conn.log(LogLevelError, LogSchemaLoadFailed, "schema loading failed",
+				LogField{"error", err})
+		} else {
+			conn.log(LogLevelInfo, LogSchemaLoaded, "schema loaded",
+				LogField{"spaces", len(schema.Spaces)},
+				LogField{"duration", time.Since(start)})
+			conn.notify(SchemaReloaded)
+		}
+	}()
 	schema.SpacesById = make(map[uint32]*Space)
 	schema.Spaces = make(map[string]*Space)
+	schema.SequencesById = make(map[uint32]*Sequence)
+	schema.Sequences = make(map[string]*Sequence)
+	schema.FunctionsById = make(map[uint32]*Function)
+	schema.Functions = make(map[string]*Function)
+
+	// collations are referred by id from space format and index parts
+	collations := schemaCollations{names: make(collationNames)}
+	if err = conn.selectSystemSpace(vcollationSpId, &collations); err != nil {
+		return err
+	}
 
 	// reload spaces
-	resp, err = conn.Select(vspaceSpId, 0, 0, maxSchemas, IterAll, []interface{}{})
-	if err != nil {
+	spaces := schemaSpaces{collations: collations.names}
+	if err = conn.selectSystemSpace(vspaceSpId, &spaces); err != nil {
 		return err
 	}
-	for _, row := range resp.Data {
-		row := row.([]interface{})
-		space := new(Space)
-		space.Id = uint32(row[0].(uint64))
-		space.Name = row[2].(string)
-		space.Engine = row[3].(string)
-		space.FieldsCount = uint32(row[4].(uint64))
-		if len(row) >= 6 {
-			switch row5 := row[5].(type) {
-			case string:
-				space.Temporary = row5 == "temporary"
-			case map[interface{}]interface{}:
-				if temp, ok := row5["temporary"]; ok {
-					space.Temporary = temp.(bool)
-				}
+	for _, space := range spaces.spaces {
+		schema.SpacesById[space.Id] = space
+		schema.Spaces[space.Name] = space
+	}
+
+	// reload indexes
+	conn.mutex.Lock()
+	hints := supportsHints(conn.Greeting.Version)
+	conn.mutex.Unlock()
+	indexes := schemaIndexes{collations: collations.names, hints: hints}
+	if err = conn.selectSystemSpace(vindexSpId, &indexes); err != nil {
+		return err
+	}
+	for i, index := range indexes.indexes {
+		space, ok := schema.SpacesById[indexes.spaceIds[i]]
+		if !ok {
+			// space is not visible for the user
+			continue
+		}
+		space.IndexesById[index.Id] = index
+		space.Indexes[index.Name] = index
+	}
+
+	// reload sequences
+	sequences := schemaSequences{}
+	if err = conn.selectSystemSpace(vsequenceSpId, &sequences); err != nil {
+		return err
+	}
+	for _, seq := range sequences.sequences {
+		schema.SequencesById[seq.Id] = seq
+		schema.Sequences[seq.Name] = seq
+	}
+
+	// reload functions
+	functions := schemaFunctions{}
+	if err = conn.selectSystemSpace(vfuncSpId, &functions); err != nil {
+		return err
+	}
+	for _, fn := range functions.functions {
+		schema.FunctionsById[fn.Id] = fn
+		schema.Functions[fn.Name] = fn
+	}
+
+	conn.setSchema(schema)
+	return nil
+}
+
+// reloadSchema loads schema after reconnect. Failed loading is retried
+// until connection c is closed or replaced. Errors are logged by loadSchema.
+func (conn *Connection) reloadSchema(c net.Conn) {
+	for attempt := 0; attempt < schemaReloadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(schemaReloadDelay)
+		}
+		conn.mutex.Lock()
+		current := conn.c
+		conn.mutex.Unlock()
+		if current != c || conn.ClosedNow() {
+			return
+		}
+		if err := conn.loadSchema(); err == nil {
+			return
+		}
+	}
+}
+
+// selectSystemSpace selects all tuples from system space into result.
+// Spaces absent in older tarantool versions are treated as empty.
+func (conn *Connection) selectSystemSpace(spaceNo uint32, result interface{}) error {
+	scope := &requestScope{internal: true}
+	err := conn.selectAsync(scope, spaceNo, 0, 0, maxSchemas, IterAll, []interface{}{}).GetTyped(result)
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case Error:
+		if err.Code == ErrNoSuchSpace && spaceNo != vspaceSpId && spaceNo != vindexSpId {
+			return nil
+		}
+		return err
+	case ClientError, SchemaError:
+		return err
+	default:
+		return SchemaError{fmt.Sprintf("unexpected schema format of space %d: %s", spaceNo, err)}
+	}
+}
+
+// collationNames is a map from collation numbers to names.
+type collationNames map[uint32]string
+
+// name returns name of collation with the id.
+// Id of unknown collation is formatted as a number.
+func (c collationNames) name(id uint32) string {
+	if name, ok := c[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// Decoders of system spaces tuples.
+//
+// Tuples are decoded field by field. Required fields (ids and names) should
+// have expected types, otherwise SchemaError is returned. Optional fields
+// and map entries of unexpected types are skipped, as well as unknown
+// fields and map keys, so schema could be loaded from newer tarantool
+// versions.
+
+type schemaCollations struct {
+	names collationNames
+}
+
+func (c *schemaCollations) DecodeMsgpack(d *msgpack.Decoder) error {
+	return decodeSchemaTuples(d, "_vcollation", 2, func(fields int) (err error) {
+		var id uint32
+		var name string
+		for i := 0; i < fields && err == nil; i++ {
+			switch i {
+			case 0:
+				id, err = d.DecodeUint32()
+			case 1:
+				name, err = d.DecodeString()
 			default:
-				panic("unexpected schema format (space flags)")
+				err = d.Skip()
 			}
 		}
+		if err == nil {
+			c.names[id] = name
+		}
+		return
+	})
+}
+
+type schemaSpaces struct {
+	collations collationNames
+	spaces     []*Space
+}
+
+func (s *schemaSpaces) DecodeMsgpack(d *msgpack.Decoder) error {
+	return decodeSchemaTuples(d, "_vspace", 5, func(fields int) (err error) {
+		space := new(Space)
 		space.FieldsById = make(map[uint32]*Field)
 		space.Fields = make(map[string]*Field)
 		space.IndexesById = make(map[uint32]*Index)
 		space.Indexes = make(map[string]*Index)
-		if len(row) >= 7 {
-			for i, f := range row[6].([]interface{}) {
-				if f == nil {
-					continue
-				}
-				f := f.(map[interface{}]interface{})
-				field := new(Field)
-				field.Id = uint32(i)
-				if name, ok := f["name"]; ok && name != nil {
-					field.Name = name.(string)
-				}
-				if type1, ok := f["type"]; ok && type1 != nil {
-					field.Type = type1.(string)
-				}
-				space.FieldsById[field.Id] = field
-				if field.Name != "" {
-					space.Fields[field.Name] = field
+		for i := 0; i < fields && err == nil; i++ {
+			switch i {
+			case 0:
+				space.Id, err = d.DecodeUint32()
+			case 2:
+				space.Name, err = d.DecodeString()
+			case 3:
+				space.Engine, err = d.DecodeString()
+			case 4:
+				space.FieldsCount, err = d.DecodeUint32()
+			case 5:
+				err = s.decodeFlags(d, space)
+			case 6:
+				err = s.decodeFormat(d, space)
+			default:
+				err = d.Skip()
+			}
+		}
+		if err == nil {
+			s.spaces = append(s.spaces, space)
+		}
+		return
+	})
+}
+
+func (s *schemaSpaces) decodeFlags(d *msgpack.Decoder, space *Space) error {
+	c, err := d.PeekCode()
+	if err != nil {
+		return err
+	}
+	switch {
+	case isStringCode(c):
+		// tarantool 1.6 stores flags as a string
+		flags, err := d.DecodeString()
+		space.Temporary = flags == "temporary"
+		return err
+	case isMapCode(c):
+		return decodeSchemaMap(d, func(key string) (err error) {
+			switch key {
+			case "temporary":
+				space.Temporary, _, err = decodeOptionalBool(d)
+			case "group_id":
+				var group uint64
+				group, _, err = decodeOptionalUint(d)
+				space.IsLocal = group == 1
+			case "is_sync":
+				space.IsSync, _, err = decodeOptionalBool(d)
+			default:
+				err = d.Skip()
+			}
+			return
+		})
+	default:
+		return d.Skip()
+	}
+}
+
+func (s *schemaSpaces) decodeFormat(d *msgpack.Decoder, space *Space) error {
+	return decodeSchemaArray(d, func(i int) error {
+		c, err := d.PeekCode()
+		if err != nil {
+			return err
+		}
+		if !isMapCode(c) {
+			return d.Skip()
+		}
+		field := new(Field)
+		field.Id = uint32(i)
+		err = decodeSchemaMap(d, func(key string) (err error) {
+			switch key {
+			case "name":
+				field.Name, _, err = decodeOptionalString(d)
+			case "type":
+				field.Type, _, err = decodeOptionalString(d)
+			case "is_nullable":
+				field.IsNullable, _, err = decodeOptionalBool(d)
+			case "collation":
+				var id uint64
+				var ok bool
+				if id, ok, err = decodeOptionalUint(d); ok {
+					field.Collation = s.collations.name(uint32(id))
 				}
+			default:
+				err = d.Skip()
 			}
+			return
+		})
+		if err != nil {
+			return err
+		}
+		space.FieldsById[field.Id] = field
+		if field.Name != "" {
+			space.Fields[field.Name] = field
 		}
+		return nil
+	})
+}
 
-		schema.SpacesById[space.Id] = space
-		schema.Spaces[space.Name] = space
+type schemaIndexes struct {
+	collations collationNames
+	// hints tells if tarantool supports hints, so TREE indexes use them
+	// unless hint option is disabled.
+	hints    bool
+	spaceIds []uint32
+	indexes  []*Index
+}
+
+// hintsVersion is a first tarantool version with TREE index hints.
+var hintsVersion = [3]uint64{2, 6, 1}
+
+// supportsHints reports if tarantool with version from greeting supports
+// TREE index hints.
+func supportsHints(greeting string) bool {
+	var v [3]uint64
+	if _, err := fmt.Sscanf(greeting, "Tarantool %d.%d.%d", &v[0], &v[1], &v[2]); err != nil {
+		return false
+	}
+	for i := range v {
+		if v[i] != hintsVersion[i] {
+			return v[i] > hintsVersion[i]
+		}
 	}
+	return true
+}
 
-	// reload indexes
-	resp, err = conn.Select(vindexSpId, 0, 0, maxSchemas, IterAll, []interface{}{})
+func (s *schemaIndexes) DecodeMsgpack(d *msgpack.Decoder) error {
+	return decodeSchemaTuples(d, "_vindex", 6, func(fields int) (err error) {
+		var spaceId uint32
+		var partsCount int
+		index := new(Index)
+		index.Unique = true
+		index.Opts = make(map[string]interface{})
+		for i := 0; i < fields && err == nil; i++ {
+			switch {
+			case i == 0:
+				spaceId, err = d.DecodeUint32()
+			case i == 1:
+				index.Id, err = d.DecodeUint32()
+			case i == 2:
+				index.Name, err = d.DecodeString()
+			case i == 3:
+				index.Type, err = d.DecodeString()
+			case i == 4:
+				err = s.decodeOpts(d, index)
+			case i == 5:
+				partsCount, err = s.decodeParts(d, index)
+			case i < 6+partsCount*2 && i%2 == 0:
+				// tarantool 1.6 stores parts as pairs of fields
+				field := new(IndexField)
+				field.Id, err = d.DecodeUint32()
+				index.Fields = append(index.Fields, field)
+			case i < 6+partsCount*2:
+				index.Fields[len(index.Fields)-1].Type, err = d.DecodeString()
+			default:
+				err = d.Skip()
+			}
+		}
+		// hint option is stored only by tarantool supporting hints
+		hint, ok := index.Opts["hint"].(bool)
+		if !ok {
+			hint = s.hints
+		}
+		index.Hint = index.Type == "TREE" && hint
+		if err == nil {
+			s.spaceIds = append(s.spaceIds, spaceId)
+			s.indexes = append(s.indexes, index)
+		}
+		return
+	})
+}
+
+func (s *schemaIndexes) decodeOpts(d *msgpack.Decoder, index *Index) error {
+	c, err := d.PeekCode()
 	if err != nil {
 		return err
 	}
-	for _, row := range resp.Data {
-		row := row.([]interface{})
-		index := new(Index)
-		index.Id = uint32(row[1].(uint64))
-		index.Name = row[2].(string)
-		index.Type = row[3].(string)
-		switch row[4].(type) {
-		case uint64:
-			index.Unique = row[4].(uint64) > 0
-		case map[interface{}]interface{}:
-			opts := row[4].(map[interface{}]interface{})
-			var ok bool
-			if index.Unique, ok = opts["unique"].(bool); !ok {
+	switch {
+	case isNumberCode(c):
+		// tarantool 1.6 stores only unique flag
+		var unique uint64
+		unique, err = d.DecodeUint64()
+		index.Unique = unique > 0
+		return err
+	case isMapCode(c):
+		return decodeSchemaMap(d, func(key string) (err error) {
+			if index.Opts[key], err = d.DecodeInterface(); err != nil {
+				return
+			}
+			if key == "unique" {
 				/* see bug https://github.com/tarantool/tarantool/issues/2060 */
-				index.Unique = true
+				if unique, ok := index.Opts[key].(bool); ok {
+					index.Unique = unique
+				}
 			}
-		default:
-			panic("unexpected schema format (index flags)")
-		}
-		switch fields := row[5].(type) {
-		case uint64:
-			cnt := int(fields)
-			for i := 0; i < cnt; i++ {
-				field := new(IndexField)
-				field.Id = uint32(row[6+i*2].(uint64))
-				field.Type = row[7+i*2].(string)
+			return
+		})
+	default:
+		return d.Skip()
+	}
+}
+
+// decodeParts decodes index parts. It returns number of parts stored in
+// following fields of tuple in tarantool 1.6 format.
+func (s *schemaIndexes) decodeParts(d *msgpack.Decoder, index *Index) (int, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case isNumberCode(c):
+		count, err := d.DecodeUint32()
+		return int(count), err
+	case isArrayCode(c):
+		return 0, decodeSchemaArray(d, func(i int) error {
+			field, err := s.decodePart(d)
+			if err == nil && field != nil {
 				index.Fields = append(index.Fields, field)
 			}
-		case []interface{}:
-			for _, f := range fields {
-				field := new(IndexField)
-				switch f := f.(type) {
-				case []interface{}:
-					field.Id = uint32(f[0].(uint64))
-					field.Type = f[1].(string)
-				case map[interface{}]interface{}:
-					field.Id = uint32(f["field"].(uint64))
-					field.Type = f["type"].(string)
+			return err
+		})
+	default:
+		return 0, d.Skip()
+	}
+}
+
+func (s *schemaIndexes) decodePart(d *msgpack.Decoder) (*IndexField, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	field := new(IndexField)
+	switch {
+	case isArrayCode(c):
+		err = decodeSchemaArray(d, func(i int) (err error) {
+			switch i {
+			case 0:
+				field.Id, err = d.DecodeUint32()
+			case 1:
+				field.Type, _, err = decodeOptionalString(d)
+			default:
+				err = d.Skip()
+			}
+			return
+		})
+	case isMapCode(c):
+		err = decodeSchemaMap(d, func(key string) (err error) {
+			switch key {
+			case "field":
+				field.Id, err = d.DecodeUint32()
+			case "type":
+				field.Type, _, err = decodeOptionalString(d)
+			case "is_nullable":
+				field.IsNullable, _, err = decodeOptionalBool(d)
+			case "path":
+				field.Path, _, err = decodeOptionalString(d)
+			case "collation":
+				var id uint64
+				var ok bool
+				if id, ok, err = decodeOptionalUint(d); ok {
+					field.Collation = s.collations.name(uint32(id))
 				}
-				index.Fields = append(index.Fields, field)
+			default:
+				err = d.Skip()
 			}
-		default:
-			panic("unexpected schema format (index fields)")
+			return
+		})
+	default:
+		return nil, d.Skip()
+	}
+	return field, err
+}
+
+type schemaSequences struct {
+	sequences []*Sequence
+}
+
+func (s *schemaSequences) DecodeMsgpack(d *msgpack.Decoder) error {
+	return decodeSchemaTuples(d, "_vsequence", 3, func(fields int) (err error) {
+		seq := new(Sequence)
+		for i := 0; i < fields && err == nil; i++ {
+			switch i {
+			case 0:
+				seq.Id, err = d.DecodeUint32()
+			case 2:
+				seq.Name, err = d.DecodeString()
+			case 3:
+				seq.Step, _, err = decodeOptionalInt(d)
+			case 4:
+				seq.Min, _, err = decodeOptionalInt(d)
+			case 5:
+				seq.Max, _, err = decodeOptionalInt(d)
+			case 6:
+				seq.Start, _, err = decodeOptionalInt(d)
+			case 7:
+				seq.Cache, _, err = decodeOptionalInt(d)
+			case 8:
+				seq.Cycle, _, err = decodeOptionalBool(d)
+			default:
+				err = d.Skip()
+			}
+		}
+		if err == nil {
+			s.sequences = append(s.sequences, seq)
+		}
+		return
+	})
+}
+
+type schemaFunctions struct {
+	functions []*Function
+}
+
+func (s *schemaFunctions) DecodeMsgpack(d *msgpack.Decoder) error {
+	return decodeSchemaTuples(d, "_vfunc", 3, func(fields int) (err error) {
+		fn := new(Function)
+		for i := 0; i < fields && err == nil; i++ {
+			switch i {
+			case 0:
+				fn.Id, err = d.DecodeUint32()
+			case 2:
+				fn.Name, err = d.DecodeString()
+			case 3:
+				var setuid uint64
+				setuid, _, err = decodeOptionalUint(d)
+				fn.SetUID = setuid != 0
+			case 4:
+				fn.Language, _, err = decodeOptionalString(d)
+			case 5:
+				fn.Body, _, err = decodeOptionalString(d)
+			case 6:
+				fn.RoutineType, _, err = decodeOptionalString(d)
+			case 7:
+				fn.Params, err = decodeOptionalStrings(d)
+			case 8:
+				fn.Returns, _, err = decodeOptionalString(d)
+			case 11:
+				fn.IsDeterministic, _, err = decodeOptionalBool(d)
+			case 12:
+				fn.IsSandboxed, _, err = decodeOptionalBool(d)
+			case 14:
+				fn.Exports, err = decodeOptionalStrings(d)
+			default:
+				err = d.Skip()
+			}
+		}
+		if err == nil {
+			s.functions = append(s.functions, fn)
+		}
+		return
+	})
+}
+
+// decodeSchemaTuples decodes array of tuples of system space.
+// decodeTuple is called for each tuple with at least minFields fields,
+// it should decode or skip all fields of the tuple.
+func decodeSchemaTuples(d *msgpack.Decoder, space string, minFields int, decodeTuple func(fields int) error) error {
+	count, err := d.DecodeSliceLen()
+	if err != nil {
+		return schemaFormatError(space, err)
+	}
+	for i := 0; i < count; i++ {
+		fields, err := d.DecodeSliceLen()
+		if err != nil {
+			return schemaFormatError(space, err)
+		}
+		if fields < minFields {
+			return SchemaError{fmt.Sprintf("unexpected schema format of %s: tuple has %d fields, expected at least %d",
+				space, fields, minFields)}
+		}
+		if err = decodeTuple(fields); err != nil {
+			return schemaFormatError(space, err)
 		}
-		spaceId := uint32(row[0].(uint64))
-		schema.SpacesById[spaceId].IndexesById[index.Id] = index
-		schema.SpacesById[spaceId].Indexes[index.Name] = index
 	}
-	conn.Schema = schema
 	return nil
 }
 
+func schemaFormatError(space string, err error) error {
+	if _, ok := err.(SchemaError); ok {
+		return err
+	}
+	return SchemaError{fmt.Sprintf("unexpected schema format of %s: %s", space, err)}
+}
+
+// decodeSchemaArray calls decodeItem for each item of array,
+// decodeItem should decode or skip the item.
+// Values of other types are skipped.
+func decodeSchemaArray(d *msgpack.Decoder, decodeItem func(i int) error) error {
+	c, err := d.PeekCode()
+	if err != nil {
+		return err
+	}
+	if !isArrayCode(c) {
+		return d.Skip()
+	}
+	l, err := d.DecodeSliceLen()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < l; i++ {
+		if err = decodeItem(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSchemaMap calls decodeValue for each entry of map with string key,
+// decodeValue should decode or skip the value.
+// Entries with keys of other types are skipped.
+func decodeSchemaMap(d *msgpack.Decoder, decodeValue func(key string) error) error {
+	l, err := d.DecodeMapLen()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < l; i++ {
+		c, err := d.PeekCode()
+		if err != nil {
+			return err
+		}
+		if !isStringCode(c) {
+			if err = d.Skip(); err != nil {
+				return err
+			}
+			if err = d.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		key, err := d.DecodeString()
+		if err != nil {
+			return err
+		}
+		if err = decodeValue(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeOptionalBool decodes boolean, values of other types are skipped.
+func decodeOptionalBool(d *msgpack.Decoder) (v bool, ok bool, err error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return
+	}
+	if c != codes.True && c != codes.False {
+		return false, false, d.Skip()
+	}
+	v, err = d.DecodeBool()
+	return v, err == nil, err
+}
+
+// decodeOptionalString decodes string, values of other types are skipped.
+func decodeOptionalString(d *msgpack.Decoder) (v string, ok bool, err error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return
+	}
+	if !isStringCode(c) {
+		return "", false, d.Skip()
+	}
+	v, err = d.DecodeString()
+	return v, err == nil, err
+}
+
+// decodeOptionalUint decodes unsigned integer, booleans are decoded as 0
+// and 1, values of other types are skipped.
+func decodeOptionalUint(d *msgpack.Decoder) (v uint64, ok bool, err error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return
+	}
+	switch {
+	case c == codes.True || c == codes.False:
+		var b bool
+		if b, err = d.DecodeBool(); b {
+			v = 1
+		}
+	case isNumberCode(c):
+		v, err = d.DecodeUint64()
+	default:
+		return 0, false, d.Skip()
+	}
+	return v, err == nil, err
+}
+
+// decodeOptionalInt decodes signed integer, values of other types are
+// skipped.
+func decodeOptionalInt(d *msgpack.Decoder) (v int64, ok bool, err error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return
+	}
+	if !isNumberCode(c) {
+		return 0, false, d.Skip()
+	}
+	v, err = d.DecodeInt64()
+	return v, err == nil, err
+}
+
+// decodeOptionalStrings decodes array of strings, items of other types
+// are skipped, as well as values which are not arrays.
+func decodeOptionalStrings(d *msgpack.Decoder) (res []string, err error) {
+	err = decodeSchemaArray(d, func(i int) error {
+		s, ok, err := decodeOptionalString(d)
+		if ok {
+			res = append(res, s)
+		}
+		return err
+	})
+	return
+}
+
+func isNumberCode(c byte) bool {
+	return codes.IsFixedNum(c) ||
+		(c >= codes.Uint8 && c <= codes.Uint64) ||
+		(c >= codes.Int8 && c <= codes.Int64)
+}
+
+func isStringCode(c byte) bool {
+	return codes.IsFixedString(c) || (c >= codes.Str8 && c <= codes.Str32)
+}
+
+func isArrayCode(c byte) bool {
+	return codes.IsFixedArray(c) || c == codes.Array16 || c == codes.Array32
+}
+
+func isMapCode(c byte) bool {
+	return codes.IsFixedMap(c) || c == codes.Map16 || c == codes.Map32
+}
+
 func (schema *Schema) resolveSpaceIndex(s interface{}, i interface{}) (spaceNo, indexNo uint32, err error) {
 	var space *Space
 	var index *Index
@@ -182,11 +846,11 @@ func (schema *Schema) resolveSpaceIndex(s interface{}, i interface{}) (spaceNo,
 	switch s := s.(type) {
 	case string:
 		if schema == nil {
-			err = fmt.Errorf("Schema is not loaded")
+			err = SchemaError{"Schema is not loaded"}
 			return
 		}
 		if space, ok = schema.Spaces[s]; !ok {
-			err = fmt.Errorf("there is no space with name %s", s)
+			err = SchemaError{fmt.Sprintf("there is no space with name %s", s)}
 			return
 		}
 		spaceNo = space.Id
@@ -215,24 +879,36 @@ func (schema *Schema) resolveSpaceIndex(s interface{}, i interface{}) (spaceNo,
 	case *Space:
 		spaceNo = s.Id
 	default:
-		panic("unexpected type of space param")
+		// types defined on top of integers and strings
+		v := reflect.ValueOf(s)
+		switch v.Kind() {
+		case reflect.String:
+			return schema.resolveSpaceIndex(v.String(), i)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			spaceNo = uint32(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			spaceNo = uint32(v.Uint())
+		default:
+			err = SchemaError{fmt.Sprintf("unexpected type of space param: %T", s)}
+			return
+		}
 	}
 
 	if i != nil {
 		switch i := i.(type) {
 		case string:
 			if schema == nil {
-				err = fmt.Errorf("Schema is not loaded")
+				err = SchemaError{"Schema is not loaded"}
 				return
 			}
 			if space == nil {
 				if space, ok = schema.SpacesById[spaceNo]; !ok {
-					err = fmt.Errorf("there is no space with id %d", spaceNo)
+					err = SchemaError{fmt.Sprintf("there is no space with id %d", spaceNo)}
 					return
 				}
 			}
 			if index, ok = space.Indexes[i]; !ok {
-				err = fmt.Errorf("space %s has not index with name %s", space.Name, i)
+				err = SchemaError{fmt.Sprintf("space %s has not index with name %s", space.Name, i)}
 				return
 			}
 			indexNo = index.Id
@@ -261,9 +937,33 @@ func (schema *Schema) resolveSpaceIndex(s interface{}, i interface{}) (spaceNo,
 		case *Index:
 			indexNo = i.Id
 		default:
-			panic("unexpected type of index param")
+			v := reflect.ValueOf(i)
+			switch v.Kind() {
+			case reflect.String:
+				return schema.resolveSpaceIndex(spaceNo, v.String())
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				indexNo = uint32(v.Int())
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				indexNo = uint32(v.Uint())
+			default:
+				err = SchemaError{fmt.Sprintf("unexpected type of index param: %T", i)}
+				return
+			}
 		}
 	}
 
 	return
 }
+
+// validateOps checks update operations built with Operations against
+// format of space. Other representations of operations are not checked.
+func (schema *Schema) validateOps(spaceNo uint32, ops interface{}) error {
+	o, ok := ops.(*Operations)
+	if !ok {
+		return nil
+	}
+	if schema == nil {
+		return o.Err()
+	}
+	return o.Validate(schema.SpacesById[spaceNo])
+}
@@ -0,0 +1,266 @@
+package tarantool_test
+
+import (
+	"testing"
+
+	. "github.com/tarantool/go-tarantool"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func marshalRows(t *testing.T, rows ...interface{}) []byte {
+	b, err := msgpack.Marshal(rows)
+	if err != nil {
+		t.Fatalf("Failed to encode rows: %s", err)
+	}
+	return b
+}
+
+func TestDecodeSpaces(t *testing.T) {
+	collations := map[uint32]string{1: "unicode", 2: "unicode_ci"}
+	b := marshalRows(t,
+		// tarantool 1.6
+		[]interface{}{uint(512), uint(1), "old", "memtx", uint(0), "temporary"},
+		// tarantool 2.x
+		[]interface{}{uint(513), uint(1), "new", "vinyl", uint(2),
+			map[string]interface{}{"group_id": uint(1), "is_sync": true, "unknown": []int{1}},
+			[]interface{}{
+				map[string]interface{}{"name": "id", "type": "unsigned"},
+				map[string]interface{}{"name": "name", "type": "string",
+					"is_nullable": true, "collation": uint(2), "default": "x"},
+				map[string]interface{}{"name": "note", "collation": uint(10)},
+			},
+		},
+		// unknown future format: flags and format of other types,
+		// format entries of other types and extra fields
+		[]interface{}{uint(514), uint(1), "future", "memtx", uint(0),
+			[]interface{}{"temporary"},
+			[]interface{}{nil, "field", map[string]interface{}{"name": 1, "type": "any"}},
+			"extra", map[string]interface{}{"a": 1},
+		},
+	)
+	spaces, err := DecodeSpaces(b, collations)
+	if err != nil {
+		t.Fatalf("Failed to decode spaces: %s", err)
+	}
+	if len(spaces) != 3 {
+		t.Fatalf("Unexpected number of spaces: %d", len(spaces))
+	}
+
+	old := spaces[0]
+	if old.Id != 512 || old.Name != "old" || old.Engine != "memtx" || !old.Temporary {
+		t.Errorf("Unexpected space: %+v", old)
+	}
+
+	new := spaces[1]
+	if new.Id != 513 || new.Name != "new" || new.FieldsCount != 2 || !new.IsLocal || !new.IsSync || new.Temporary {
+		t.Errorf("Unexpected space: %+v", new)
+	}
+	if len(new.FieldsById) != 3 || len(new.Fields) != 3 {
+		t.Fatalf("Unexpected fields: %v", new.FieldsById)
+	}
+	if f := new.Fields["name"]; f.Id != 1 || f.Type != "string" || !f.IsNullable || f.Collation != "unicode_ci" {
+		t.Errorf("Unexpected field: %+v", f)
+	}
+	if f := new.Fields["id"]; f.Id != 0 || f.Type != "unsigned" || f.IsNullable || f.Collation != "" {
+		t.Errorf("Unexpected field: %+v", f)
+	}
+	if f := new.Fields["note"]; f.Collation != "10" {
+		t.Errorf("Unexpected collation of field: %+v", f)
+	}
+
+	future := spaces[2]
+	if future.Id != 514 || future.Temporary {
+		t.Errorf("Unexpected space: %+v", future)
+	}
+	if f, ok := future.FieldsById[2]; !ok || f.Name != "" || f.Type != "any" {
+		t.Errorf("Unexpected fields: %v", future.FieldsById)
+	}
+}
+
+func TestDecodeSpacesErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		rows []interface{}
+	}{
+		{"short tuple", []interface{}{[]interface{}{uint(512), uint(1), "short"}}},
+		{"name type", []interface{}{[]interface{}{uint(512), uint(1), 5, "memtx", uint(0)}}},
+		{"id type", []interface{}{[]interface{}{"512", uint(1), "name", "memtx", uint(0)}}},
+		{"tuple type", []interface{}{"tuple"}},
+	}
+	for _, c := range cases {
+		_, err := DecodeSpaces(marshalRows(t, c.rows...), nil)
+		if _, ok := err.(SchemaError); !ok {
+			t.Errorf("%s: expected SchemaError, got %#v", c.name, err)
+		}
+	}
+}
+
+func TestDecodeIndexes(t *testing.T) {
+	collations := map[uint32]string{2: "unicode_ci"}
+	b := marshalRows(t,
+		// tarantool 1.6
+		[]interface{}{uint(512), uint(0), "primary", "TREE", uint(1), uint(2),
+			uint(0), "num", uint(1), "str"},
+		// tarantool 1.7
+		[]interface{}{uint(512), uint(1), "secondary", "HASH",
+			map[string]interface{}{"unique": false},
+			[]interface{}{[]interface{}{uint(1), "unsigned"}}},
+		// tarantool 2.x
+		[]interface{}{uint(513), uint(2), "json", "TREE",
+			map[string]interface{}{"unique": true, "hint": false, "bloom_fpr": 0.05},
+			[]interface{}{
+				map[string]interface{}{"field": uint(2), "type": "string",
+					"is_nullable": true, "collation": uint(2), "sort_order": "asc"},
+				map[string]interface{}{"field": uint(3), "type": "unsigned", "path": "a.b[*]"},
+			}},
+		// unknown future format
+		[]interface{}{uint(513), uint(3), "future", "TREE", "opts", "parts", "extra"},
+	)
+	indexes, spaceIds, err := DecodeIndexes(b, collations, true)
+	if err != nil {
+		t.Fatalf("Failed to decode indexes: %s", err)
+	}
+	if len(indexes) != 4 || len(spaceIds) != 4 {
+		t.Fatalf("Unexpected number of indexes: %d", len(indexes))
+	}
+	if spaceIds[0] != 512 || spaceIds[2] != 513 {
+		t.Errorf("Unexpected space ids: %v", spaceIds)
+	}
+
+	old := indexes[0]
+	if old.Id != 0 || old.Name != "primary" || old.Type != "TREE" || !old.Unique || len(old.Fields) != 2 {
+		t.Fatalf("Unexpected index: %+v", old)
+	}
+	if old.Fields[0].Id != 0 || old.Fields[0].Type != "num" || old.Fields[1].Id != 1 || old.Fields[1].Type != "str" {
+		t.Errorf("Unexpected index parts: %+v, %+v", old.Fields[0], old.Fields[1])
+	}
+
+	secondary := indexes[1]
+	if secondary.Unique || secondary.Hint || len(secondary.Fields) != 1 || secondary.Fields[0].Type != "unsigned" {
+		t.Errorf("Unexpected index: %+v", secondary)
+	}
+
+	json := indexes[2]
+	if !json.Unique || json.Hint || len(json.Fields) != 2 {
+		t.Fatalf("Unexpected index: %+v", json)
+	}
+	if _, ok := json.Opts["bloom_fpr"]; !ok {
+		t.Errorf("Index option is not loaded: %v", json.Opts)
+	}
+	if p := json.Fields[0]; p.Id != 2 || !p.IsNullable || p.Collation != "unicode_ci" || p.Path != "" {
+		t.Errorf("Unexpected index part: %+v", p)
+	}
+	if p := json.Fields[1]; p.Id != 3 || p.IsNullable || p.Path != "a.b[*]" {
+		t.Errorf("Unexpected index part: %+v", p)
+	}
+
+	future := indexes[3]
+	if !future.Unique || !future.Hint || len(future.Fields) != 0 {
+		t.Errorf("Unexpected index: %+v", future)
+	}
+
+	// tarantool before 2.6.1 has no hints
+	indexes, _, err = DecodeIndexes(b, collations, false)
+	if err != nil {
+		t.Fatalf("Failed to decode indexes: %s", err)
+	}
+	for _, index := range indexes {
+		if index.Hint {
+			t.Errorf("Index %s uses hints without hints support", index.Name)
+		}
+	}
+
+	_, _, err = DecodeIndexes(marshalRows(t, []interface{}{uint(512), uint(0), "primary", "TREE", uint(1)}), nil, true)
+	if _, ok := err.(SchemaError); !ok {
+		t.Errorf("Expected SchemaError, got %#v", err)
+	}
+}
+
+func TestSupportsHints(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"Tarantool 1.10.15 (Binary) 7ab3a4c8-2f0f-4e0b-b4d3-1f7b2b1c5a11": false,
+		"Tarantool 2.6.0 (Binary) 7ab3a4c8-2f0f-4e0b-b4d3-1f7b2b1c5a11":   false,
+		"Tarantool 2.6.1 (Binary) 7ab3a4c8-2f0f-4e0b-b4d3-1f7b2b1c5a11":   true,
+		"Tarantool 2.11.0-entrypoint (Binary) 7ab3a4c8-2f0f-4e0b-b4d3":    true,
+		"Tarantool 3.0.0 (Binary) 7ab3a4c8-2f0f-4e0b-b4d3-1f7b2b1c5a11":   true,
+		"": false,
+	} {
+		if supports := SupportsHints(version); supports != expected {
+			t.Errorf("SupportsHints(%q) = %v, expected %v", version, supports, expected)
+		}
+	}
+}
+
+func TestDecodeSequencesAndFunctions(t *testing.T) {
+	b := marshalRows(t,
+		[]interface{}{uint(1), uint(1), "seq", int64(-2), int64(-100), uint64(1) << 63 >> 1, uint(10), uint(0), true},
+		[]interface{}{uint(2), uint(1), "short"},
+	)
+	sequences, err := DecodeSequences(b)
+	if err != nil {
+		t.Fatalf("Failed to decode sequences: %s", err)
+	}
+	if len(sequences) != 2 {
+		t.Fatalf("Unexpected number of sequences: %d", len(sequences))
+	}
+	if s := sequences[0]; s.Name != "seq" || s.Step != -2 || s.Min != -100 || s.Start != 10 || !s.Cycle {
+		t.Errorf("Unexpected sequence: %+v", s)
+	}
+
+	b = marshalRows(t,
+		// tarantool 1.10
+		[]interface{}{uint(1), uint(1), "box.info", uint(0), "LUA"},
+		// tarantool 2.x
+		[]interface{}{uint(2), uint(1), "sum", uint(1), "LUA", "function(a, b) return a + b end",
+			"function", []interface{}{"number", "number"}, "number", "none", "none",
+			true, false, true, []interface{}{"LUA", "SQL"}, map[string]interface{}{}, ""},
+	)
+	functions, err := DecodeFunctions(b)
+	if err != nil {
+		t.Fatalf("Failed to decode functions: %s", err)
+	}
+	if len(functions) != 2 {
+		t.Fatalf("Unexpected number of functions: %d", len(functions))
+	}
+	if f := functions[0]; f.Name != "box.info" || f.Language != "LUA" || f.SetUID {
+		t.Errorf("Unexpected function: %+v", f)
+	}
+	f := functions[1]
+	if f.Name != "sum" || !f.SetUID || f.Returns != "number" || !f.IsDeterministic || f.IsSandboxed {
+		t.Errorf("Unexpected function: %+v", f)
+	}
+	if len(f.Params) != 2 || len(f.Exports) != 2 || f.Exports[1] != "SQL" {
+		t.Errorf("Unexpected function params or exports: %+v", f)
+	}
+}
+
+type aliasSpaceNo uint32
+type aliasIndexName string
+
+func TestResolveSpaceIndexTypes(t *testing.T) {
+	schema := &Schema{
+		Spaces:     map[string]*Space{},
+		SpacesById: map[uint32]*Space{},
+	}
+	space := &Space{Id: 512, Name: "test", Indexes: map[string]*Index{"sec": {Id: 2, Name: "sec"}}}
+	schema.Spaces["test"] = space
+	schema.SpacesById[512] = space
+
+	spaceNo, indexNo, err := schema.ResolveSpaceIndex(aliasSpaceNo(512), aliasIndexName("sec"))
+	if err != nil || spaceNo != 512 || indexNo != 2 {
+		t.Errorf("Unexpected resolve result: %d, %d, %v", spaceNo, indexNo, err)
+	}
+
+	_, _, err = schema.ResolveSpaceIndex(1.5, nil)
+	if _, ok := err.(SchemaError); !ok {
+		t.Errorf("Expected SchemaError for float space, got %#v", err)
+	}
+	_, _, err = schema.ResolveSpaceIndex(512, []int{1})
+	if _, ok := err.(SchemaError); !ok {
+		t.Errorf("Expected SchemaError for slice index, got %#v", err)
+	}
+	_, _, err = (*Schema)(nil).ResolveSpaceIndex("test", nil)
+	if _, ok := err.(SchemaError); !ok {
+		t.Errorf("Expected SchemaError for not loaded schema, got %#v", err)
+	}
+}
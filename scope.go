@@ -0,0 +1,302 @@
+package tarantool
+
+import (
+	"context"
+	"time"
+)
+
+// requestScope holds options applied to requests made with ScopedConnection.
+type requestScope struct {
+	ctx context.Context
+	// timeout overrides Opts.Timeout if hasTimeout is set.
+	timeout    time.Duration
+	hasTimeout bool
+	deadline   time.Time
+	// idempotent requests are retried according to Opts.Retry.
+	idempotent bool
+	// noRetry disables retries, it is set for attempts of retried request.
+	noRetry bool
+	// internal is set for requests made by connection itself (schema
+	// loading and pings), they are not limited by request rate.
+	internal bool
+}
+
+// sleep pauses before next attempt of request. It returns false if pause
+// would exceed deadline of scope or if context is done.
+func (scope *requestScope) sleep(pause time.Duration) bool {
+	wakeup := time.Now().Add(pause)
+	if !scope.deadline.IsZero() && wakeup.After(scope.deadline) {
+		return false
+	}
+	if scope.ctx == nil {
+		time.Sleep(pause)
+		return true
+	}
+	if d, ok := scope.ctx.Deadline(); ok && wakeup.After(d) {
+		return false
+	}
+	t := time.NewTimer(pause)
+	defer t.Stop()
+	select {
+	case <-scope.ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// expiration returns moment when request started at start is timeouted.
+// It is the earliest of request timeout, scope deadline and deadline of
+// context. Zero time means request has no timeout.
+func (scope *requestScope) expiration(conn *Connection, start time.Time) (deadline time.Time) {
+	timeout := conn.opts.Timeout
+	if scope != nil && scope.hasTimeout {
+		timeout = scope.timeout
+	}
+	if timeout > 0 {
+		deadline = start.Add(timeout)
+	}
+	if scope == nil {
+		return
+	}
+	if !scope.deadline.IsZero() && (deadline.IsZero() || scope.deadline.Before(deadline)) {
+		deadline = scope.deadline
+	}
+	if scope.ctx != nil {
+		if d, ok := scope.ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+			deadline = d
+		}
+	}
+	return
+}
+
+// ScopedConnection is a view of Connection, which applies options to
+// all requests made with it. It shares connection, so it should not be
+// closed separately, though Close closes underlying connection.
+type ScopedConnection struct {
+	conn  *Connection
+	scope requestScope
+}
+
+var _ = Connector(&ScopedConnection{}) // check compatibility with connector interface
+
+// WithContext returns view of connection, which requests carry ctx.
+// Context is passed to interceptors in RequestInfo.Context, so it could be
+// used to propagate tracing spans.
+func (conn *Connection) WithContext(ctx context.Context) *ScopedConnection {
+	return &ScopedConnection{conn: conn, scope: requestScope{ctx: ctx}}
+}
+
+// WithTimeout returns view of connection, which requests use timeout instead
+// of Opts.Timeout. Non-positive timeout disables timeout of requests.
+func (conn *Connection) WithTimeout(timeout time.Duration) *ScopedConnection {
+	return &ScopedConnection{conn: conn, scope: requestScope{timeout: timeout, hasTimeout: true}}
+}
+
+// WithDeadline returns view of connection, which requests are timeouted at
+// deadline, if it is earlier than Opts.Timeout.
+func (conn *Connection) WithDeadline(deadline time.Time) *ScopedConnection {
+	return &ScopedConnection{conn: conn, scope: requestScope{deadline: deadline}}
+}
+
+// WithContext returns copy of view with context replaced.
+func (s *ScopedConnection) WithContext(ctx context.Context) *ScopedConnection {
+	scoped := *s
+	scoped.scope.ctx = ctx
+	return &scoped
+}
+
+// WithTimeout returns copy of view with request timeout replaced.
+// Non-positive timeout disables timeout of requests.
+func (s *ScopedConnection) WithTimeout(timeout time.Duration) *ScopedConnection {
+	scoped := *s
+	scoped.scope.timeout = timeout
+	scoped.scope.hasTimeout = true
+	return &scoped
+}
+
+// WithDeadline returns copy of view with deadline replaced.
+func (s *ScopedConnection) WithDeadline(deadline time.Time) *ScopedConnection {
+	scoped := *s
+	scoped.scope.deadline = deadline
+	return &scoped
+}
+
+// Context returns context of view.
+func (s *ScopedConnection) Context() context.Context {
+	if s.scope.ctx == nil {
+		return context.Background()
+	}
+	return s.scope.ctx
+}
+
+// Conn returns underlying connection.
+func (s *ScopedConnection) Conn() *Connection {
+	return s.conn
+}
+
+// ConnectedNow reports if connection is established at the moment.
+func (s *ScopedConnection) ConnectedNow() bool {
+	return s.conn.ConnectedNow()
+}
+
+// Close closes underlying connection.
+func (s *ScopedConnection) Close() error {
+	return s.conn.Close()
+}
+
+// ConfiguredTimeout returns timeout of requests made with view: time left
+// until deadline, if it is earlier than request timeout.
+func (s *ScopedConnection) ConfiguredTimeout() time.Duration {
+	now := time.Now()
+	deadline := s.scope.expiration(s.conn, now)
+	if deadline.IsZero() {
+		return 0
+	}
+	if timeout := deadline.Sub(now); timeout > 0 {
+		return timeout
+	}
+	return time.Nanosecond
+}
+
+// Ping sends empty request to Tarantool to check connection.
+func (s *ScopedConnection) Ping() (resp *Response, err error) {
+	return s.conn.pingAsync(&s.scope).Get()
+}
+
+// Select performs select to box space.
+func (s *ScopedConnection) Select(space, index interface{}, offset, limit, iterator uint32, key interface{}) (resp *Response, err error) {
+	return s.SelectAsync(space, index, offset, limit, iterator, key).Get()
+}
+
+// Insert performs insertion to box space.
+func (s *ScopedConnection) Insert(space interface{}, tuple interface{}) (resp *Response, err error) {
+	return s.InsertAsync(space, tuple).Get()
+}
+
+// Replace performs "insert or replace" action to box space.
+func (s *ScopedConnection) Replace(space interface{}, tuple interface{}) (resp *Response, err error) {
+	return s.ReplaceAsync(space, tuple).Get()
+}
+
+// Delete performs deletion of a tuple by key.
+func (s *ScopedConnection) Delete(space, index interface{}, key interface{}) (resp *Response, err error) {
+	return s.DeleteAsync(space, index, key).Get()
+}
+
+// Update performs update of a tuple by key.
+func (s *ScopedConnection) Update(space, index interface{}, key, ops interface{}) (resp *Response, err error) {
+	return s.UpdateAsync(space, index, key, ops).Get()
+}
+
+// Upsert performs "update or insert" action of a tuple by key.
+func (s *ScopedConnection) Upsert(space interface{}, tuple, ops interface{}) (resp *Response, err error) {
+	return s.UpsertAsync(space, tuple, ops).Get()
+}
+
+// Call calls registered tarantool function using request code for tarantool 1.6.
+func (s *ScopedConnection) Call(functionName string, args interface{}) (resp *Response, err error) {
+	return s.CallAsync(functionName, args).Get()
+}
+
+// Call17 calls registered tarantool function using request code for tarantool 1.7.
+func (s *ScopedConnection) Call17(functionName string, args interface{}) (resp *Response, err error) {
+	return s.Call17Async(functionName, args).Get()
+}
+
+// Eval passes lua expression for evaluation.
+func (s *ScopedConnection) Eval(expr string, args interface{}) (resp *Response, err error) {
+	return s.EvalAsync(expr, args).Get()
+}
+
+// GetTyped performs select (with limit = 1 and offset = 0)
+// to box space and fills typed result.
+func (s *ScopedConnection) GetTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
+	res := single{res: result}
+	return s.SelectAsync(space, index, 0, 1, IterEq, key).GetTyped(&res)
+}
+
+// SelectTyped performs select to box space and fills typed result.
+func (s *ScopedConnection) SelectTyped(space, index interface{}, offset, limit, iterator uint32, key interface{}, result interface{}) (err error) {
+	return s.SelectAsync(space, index, offset, limit, iterator, key).GetTyped(result)
+}
+
+// InsertTyped performs insertion to box space.
+func (s *ScopedConnection) InsertTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
+	return s.InsertAsync(space, tuple).GetTyped(result)
+}
+
+// ReplaceTyped performs "insert or replace" action to box space.
+func (s *ScopedConnection) ReplaceTyped(space interface{}, tuple interface{}, result interface{}) (err error) {
+	return s.ReplaceAsync(space, tuple).GetTyped(result)
+}
+
+// DeleteTyped performs deletion of a tuple by key and fills result with deleted tuple.
+func (s *ScopedConnection) DeleteTyped(space, index interface{}, key interface{}, result interface{}) (err error) {
+	return s.DeleteAsync(space, index, key).GetTyped(result)
+}
+
+// UpdateTyped performs update of a tuple by key and fills result with updated tuple.
+func (s *ScopedConnection) UpdateTyped(space, index interface{}, key, ops interface{}, result interface{}) (err error) {
+	return s.UpdateAsync(space, index, key, ops).GetTyped(result)
+}
+
+// CallTyped calls registered function using request code for tarantool 1.6.
+func (s *ScopedConnection) CallTyped(functionName string, args interface{}, result interface{}) (err error) {
+	return s.CallAsync(functionName, args).GetTyped(result)
+}
+
+// Call17Typed calls registered function using request code for tarantool 1.7.
+func (s *ScopedConnection) Call17Typed(functionName string, args interface{}, result interface{}) (err error) {
+	return s.Call17Async(functionName, args).GetTyped(result)
+}
+
+// EvalTyped passes lua expression for evaluation.
+func (s *ScopedConnection) EvalTyped(expr string, args interface{}, result interface{}) (err error) {
+	return s.EvalAsync(expr, args).GetTyped(result)
+}
+
+// SelectAsync sends select request to tarantool and returns Future.
+func (s *ScopedConnection) SelectAsync(space, index interface{}, offset, limit, iterator uint32, key interface{}) *Future {
+	return s.conn.selectAsync(&s.scope, space, index, offset, limit, iterator, key)
+}
+
+// InsertAsync sends insert action to tarantool and returns Future.
+func (s *ScopedConnection) InsertAsync(space interface{}, tuple interface{}) *Future {
+	return s.conn.insertAsync(&s.scope, space, tuple)
+}
+
+// ReplaceAsync sends "insert or replace" action to tarantool and returns Future.
+func (s *ScopedConnection) ReplaceAsync(space interface{}, tuple interface{}) *Future {
+	return s.conn.replaceAsync(&s.scope, space, tuple)
+}
+
+// DeleteAsync sends deletion action to tarantool and returns Future.
+func (s *ScopedConnection) DeleteAsync(space, index interface{}, key interface{}) *Future {
+	return s.conn.deleteAsync(&s.scope, space, index, key)
+}
+
+// UpdateAsync sends update action to tarantool and returns Future.
+func (s *ScopedConnection) UpdateAsync(space, index interface{}, key, ops interface{}) *Future {
+	return s.conn.updateAsync(&s.scope, space, index, key, ops)
+}
+
+// UpsertAsync sends "update or insert" action to tarantool and returns Future.
+func (s *ScopedConnection) UpsertAsync(space interface{}, tuple interface{}, ops interface{}) *Future {
+	return s.conn.upsertAsync(&s.scope, space, tuple, ops)
+}
+
+// CallAsync sends a call to registered tarantool function and returns Future.
+func (s *ScopedConnection) CallAsync(functionName string, args interface{}) *Future {
+	return s.conn.callAsync(&s.scope, functionName, args)
+}
+
+// Call17Async sends a call to registered tarantool function and returns Future.
+func (s *ScopedConnection) Call17Async(functionName string, args interface{}) *Future {
+	return s.conn.call17Async(&s.scope, functionName, args)
+}
+
+// EvalAsync sends a lua expression for evaluation and returns Future.
+func (s *ScopedConnection) EvalAsync(expr string, args interface{}) *Future {
+	return s.conn.evalAsync(&s.scope, expr, args)
+}
@@ -0,0 +1,262 @@
+package tarantool
+
+import (
+	"sync/atomic"
+)
+
+// SpaceHandle is a handle to tarantool space returned by Connection.Space.
+//
+// Space is resolved to its number on first use and the number is cached
+// until Schema of connection is reloaded, so requests made with handle
+// do not look up space by name each time.
+type SpaceHandle struct {
+	conn  *Connection
+	space interface{}
+	cache atomic.Value // *resolvedHandle
+}
+
+// IndexHandle is a handle to index of tarantool space returned by
+// SpaceHandle.Index.
+type IndexHandle struct {
+	space *SpaceHandle
+	index interface{}
+	cache atomic.Value // *resolvedHandle
+}
+
+// resolvedHandle is space and index numbers resolved with schema.
+type resolvedHandle struct {
+	schema  *Schema
+	spaceNo uint32
+	indexNo uint32
+}
+
+// Space returns handle to the space.
+// Space could be specified by name or by number, as in any other method.
+// Existence of space is not checked until first request.
+func (conn *Connection) Space(space interface{}) *SpaceHandle {
+	return &SpaceHandle{conn: conn, space: space}
+}
+
+// Index returns handle to the index of space.
+// Index could be specified by name or by number.
+func (s *SpaceHandle) Index(index interface{}) *IndexHandle {
+	return &IndexHandle{space: s, index: index}
+}
+
+// Id returns number of space.
+func (s *SpaceHandle) Id() (uint32, error) {
+	return s.resolve()
+}
+
+func (s *SpaceHandle) resolve() (uint32, error) {
+	schema := s.conn.GetSchema()
+	if r, ok := s.cache.Load().(*resolvedHandle); ok && r.schema == schema {
+		return r.spaceNo, nil
+	}
+	spaceNo, _, err := schema.resolveSpaceIndex(s.space, nil)
+	if err != nil {
+		return 0, err
+	}
+	s.cache.Store(&resolvedHandle{schema: schema, spaceNo: spaceNo})
+	return spaceNo, nil
+}
+
+// Insert performs insertion to the space.
+//
+// It is equal to conn.Insert(space, tuple).
+func (s *SpaceHandle) Insert(tuple interface{}) (resp *Response, err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Insert(spaceNo, tuple)
+}
+
+// InsertTyped performs insertion to the space and fills typed result.
+//
+// It is equal to conn.InsertTyped(space, tuple, result).
+func (s *SpaceHandle) InsertTyped(tuple interface{}, result interface{}) (err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	return s.conn.InsertTyped(spaceNo, tuple, result)
+}
+
+// Replace performs "insert or replace" action to the space.
+//
+// It is equal to conn.Replace(space, tuple).
+func (s *SpaceHandle) Replace(tuple interface{}) (resp *Response, err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Replace(spaceNo, tuple)
+}
+
+// ReplaceTyped performs "insert or replace" action to the space and fills
+// typed result.
+//
+// It is equal to conn.ReplaceTyped(space, tuple, result).
+func (s *SpaceHandle) ReplaceTyped(tuple interface{}, result interface{}) (err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	return s.conn.ReplaceTyped(spaceNo, tuple, result)
+}
+
+// Upsert performs "update or insert" action to the space.
+//
+// It is equal to conn.Upsert(space, tuple, ops).
+func (s *SpaceHandle) Upsert(tuple, ops interface{}) (resp *Response, err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Upsert(spaceNo, tuple, ops)
+}
+
+// Update performs update of a tuple by primary key.
+//
+// It is equal to conn.Update(space, 0, key, ops).
+func (s *SpaceHandle) Update(key, ops interface{}) (resp *Response, err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Update(spaceNo, uint32(0), key, ops)
+}
+
+// UpdateTyped performs update of a tuple by primary key and fills result
+// with updated tuple.
+//
+// It is equal to conn.UpdateTyped(space, 0, key, ops, result).
+func (s *SpaceHandle) UpdateTyped(key, ops interface{}, result interface{}) (err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	return s.conn.UpdateTyped(spaceNo, uint32(0), key, ops, result)
+}
+
+// Delete performs deletion of a tuple by primary key.
+//
+// It is equal to conn.Delete(space, 0, key).
+func (s *SpaceHandle) Delete(key interface{}) (resp *Response, err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.Delete(spaceNo, uint32(0), key)
+}
+
+// DeleteTyped performs deletion of a tuple by primary key and fills result
+// with deleted tuple.
+//
+// It is equal to conn.DeleteTyped(space, 0, key, result).
+func (s *SpaceHandle) DeleteTyped(key interface{}, result interface{}) (err error) {
+	spaceNo, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	return s.conn.DeleteTyped(spaceNo, uint32(0), key, result)
+}
+
+// Id returns numbers of space and index.
+func (i *IndexHandle) Id() (spaceNo, indexNo uint32, err error) {
+	return i.resolve()
+}
+
+func (i *IndexHandle) resolve() (spaceNo, indexNo uint32, err error) {
+	schema := i.space.conn.GetSchema()
+	if r, ok := i.cache.Load().(*resolvedHandle); ok && r.schema == schema {
+		return r.spaceNo, r.indexNo, nil
+	}
+	if spaceNo, err = i.space.resolve(); err != nil {
+		return
+	}
+	if _, indexNo, err = schema.resolveSpaceIndex(spaceNo, i.index); err != nil {
+		return
+	}
+	i.cache.Store(&resolvedHandle{schema: schema, spaceNo: spaceNo, indexNo: indexNo})
+	return
+}
+
+// Select performs select by the index.
+//
+// It is equal to conn.Select(space, index, offset, limit, iterator, key).
+func (i *IndexHandle) Select(offset, limit, iterator uint32, key interface{}) (resp *Response, err error) {
+	spaceNo, indexNo, err := i.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return i.space.conn.Select(spaceNo, indexNo, offset, limit, iterator, key)
+}
+
+// SelectTyped performs select by the index and fills typed result.
+//
+// It is equal to conn.SelectTyped(space, index, offset, limit, iterator, key, result).
+func (i *IndexHandle) SelectTyped(offset, limit, iterator uint32, key interface{}, result interface{}) (err error) {
+	spaceNo, indexNo, err := i.resolve()
+	if err != nil {
+		return err
+	}
+	return i.space.conn.SelectTyped(spaceNo, indexNo, offset, limit, iterator, key, result)
+}
+
+// Get fills result with a tuple found by key.
+// Result is left untouched if there is no such tuple.
+//
+// It is equal to conn.GetTyped(space, index, key, result).
+func (i *IndexHandle) Get(key interface{}, result interface{}) (err error) {
+	spaceNo, indexNo, err := i.resolve()
+	if err != nil {
+		return err
+	}
+	return i.space.conn.GetTyped(spaceNo, indexNo, key, result)
+}
+
+// Min fills result with a tuple with the smallest key, which is greater or
+// equal to the key. Pass empty key to get the smallest tuple in the index.
+// Result is left untouched if there is no such tuple.
+// Note: it works only for TREE indexes.
+func (i *IndexHandle) Min(key interface{}, result interface{}) (err error) {
+	return i.first(IterGe, key, result)
+}
+
+// Max fills result with a tuple with the greatest key, which is less or
+// equal to the key. Pass empty key to get the greatest tuple in the index.
+// Result is left untouched if there is no such tuple.
+// Note: it works only for TREE indexes.
+func (i *IndexHandle) Max(key interface{}, result interface{}) (err error) {
+	return i.first(IterLe, key, result)
+}
+
+func (i *IndexHandle) first(iterator uint32, key interface{}, result interface{}) (err error) {
+	spaceNo, indexNo, err := i.resolve()
+	if err != nil {
+		return err
+	}
+	s := single{res: result}
+	return i.space.conn.SelectAsync(spaceNo, indexNo, 0, 1, iterator, key).GetTyped(&s)
+}
+
+// Count returns number of tuples matching the key with iterator.
+// Note: it uses Eval, so user needs 'execute universe' privilege
+func (i *IndexHandle) Count(iterator uint32, key interface{}) (count uint64, err error) {
+	spaceNo, indexNo, err := i.resolve()
+	if err != nil {
+		return 0, err
+	}
+	var res []uint64
+	expr := "local s, i, k, it = ... ; return box.space[s].index[i]:count(k, {iterator = it})"
+	err = i.space.conn.EvalTyped(expr, []interface{}{spaceNo, indexNo, key, iterator}, &res)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) > 0 {
+		count = res[0]
+	}
+	return count, nil
+}
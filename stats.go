@@ -0,0 +1,279 @@
+package tarantool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBounds are upper bounds of latency histogram buckets.
+var latencyBounds = [...]time.Duration{
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	1 * time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// statsCodes is a number of request codes tracked by statistics.
+const statsCodes = 128
+
+// Stats is a snapshot of Connection statistics.
+// Counters are accumulated since Connect.
+type Stats struct {
+	// Requests is a number of requests sent by request code.
+	Requests map[int32]uint64
+	// Responses is a number of responses received for pending requests.
+	Responses uint64
+	// UnexpectedResponses is a number of responses received for unknown
+	// (most probably timeouted) requests.
+	UnexpectedResponses uint64
+	// Errors is a number of failed requests by error code: server errors
+	// are counted by Error.Code, client errors by ClientError.Code.
+	Errors map[uint32]uint64
+	// NetworkErrors is a number of requests failed due to network errors.
+	NetworkErrors uint64
+	// Timeouts is a number of requests failed due to client timeout.
+	Timeouts uint64
+	// RateLimited is a number of requests dropped due to rate limit.
+	RateLimited uint64
+	// Reconnects is a number of successful reconnects.
+	Reconnects uint64
+	// BytesRead is a number of bytes read from socket.
+	BytesRead uint64
+	// BytesWritten is a number of bytes written to socket.
+	BytesWritten uint64
+	// InFlight is a number of requests waiting for response by shard.
+	InFlight []int
+	// Latency contains latency histograms by request code.
+	Latency map[int32]LatencyHistogram
+}
+
+// LatencyHistogram contains distribution of requests latencies.
+type LatencyHistogram struct {
+	// Bounds are upper bounds of buckets.
+	Bounds []time.Duration
+	// Counts contains number of requests in each bucket: Counts[i] is a
+	// number of requests with latency in (Bounds[i-1], Bounds[i]], last
+	// element is a number of requests with latency above all bounds.
+	Counts []uint64
+	// Count is a total number of requests.
+	Count uint64
+	// Sum is a total latency of all requests.
+	Sum time.Duration
+}
+
+// InFlightTotal returns number of requests waiting for response.
+func (s Stats) InFlightTotal() (total int) {
+	for _, n := range s.InFlight {
+		total += n
+	}
+	return
+}
+
+// Add adds counters of other to s. It is used to aggregate statistics
+// of several connections.
+func (s *Stats) Add(other Stats) {
+	if s.Requests == nil {
+		s.Requests = make(map[int32]uint64)
+	}
+	for code, n := range other.Requests {
+		s.Requests[code] += n
+	}
+	if s.Errors == nil {
+		s.Errors = make(map[uint32]uint64)
+	}
+	for code, n := range other.Errors {
+		s.Errors[code] += n
+	}
+	s.Responses += other.Responses
+	s.UnexpectedResponses += other.UnexpectedResponses
+	s.NetworkErrors += other.NetworkErrors
+	s.Timeouts += other.Timeouts
+	s.RateLimited += other.RateLimited
+	s.Reconnects += other.Reconnects
+	s.BytesRead += other.BytesRead
+	s.BytesWritten += other.BytesWritten
+	for i, n := range other.InFlight {
+		if i < len(s.InFlight) {
+			s.InFlight[i] += n
+		} else {
+			s.InFlight = append(s.InFlight, n)
+		}
+	}
+	if s.Latency == nil {
+		s.Latency = make(map[int32]LatencyHistogram)
+	}
+	for code, h := range other.Latency {
+		sh, ok := s.Latency[code]
+		if !ok {
+			sh = LatencyHistogram{Bounds: h.Bounds, Counts: make([]uint64, len(h.Counts))}
+		}
+		for i := range h.Counts {
+			sh.Counts[i] += h.Counts[i]
+		}
+		sh.Count += h.Count
+		sh.Sum += h.Sum
+		s.Latency[code] = sh
+	}
+}
+
+// connStats holds counters of a Connection.
+// It is allocated separately to keep 64-bit counters aligned.
+type connStats struct {
+	requests      [statsCodes]uint64
+	responses     uint64
+	unexpected    uint64
+	networkErrors uint64
+	timeouts      uint64
+	rateLimited   uint64
+	reconnects    uint64
+	bytesRead     uint64
+	bytesWritten  uint64
+	inFlight      int64
+	latency       [statsCodes]latencyStats
+
+	errorsMutex sync.Mutex
+	errors      map[uint32]uint64
+}
+
+type latencyStats struct {
+	// counts has an extra bucket for latencies above all bounds.
+	counts [len(latencyBounds) + 1]uint64
+	count  uint64
+	sum    uint64
+}
+
+func newConnStats() *connStats {
+	return &connStats{errors: make(map[uint32]uint64)}
+}
+
+func (s *connStats) requestSent(code int32) {
+	if code >= 0 && code < statsCodes {
+		atomic.AddUint64(&s.requests[code], 1)
+	}
+}
+
+// requestDone accounts completed request.
+func (s *connStats) requestDone(fut *Future) {
+	atomic.AddInt64(&s.inFlight, -1)
+	if fut.resp != nil {
+		atomic.AddUint64(&s.responses, 1)
+		if fut.resp.Code != OkCode {
+			s.addError(fut.resp.Code &^ ErrorCodeBit)
+		}
+	} else if fut.err != nil {
+		s.requestFailed(fut.err)
+	}
+	if sent := fut.sentAt(); sent > 0 && fut.requestCode >= 0 && fut.requestCode < statsCodes {
+		s.latency[fut.requestCode].observe(time.Now().Sub(epoch) - sent)
+	}
+}
+
+// requestFailed accounts request failed without response.
+func (s *connStats) requestFailed(err error) {
+	switch err := err.(type) {
+	case ClientError:
+		switch err.Code {
+		case ErrTimeouted:
+			atomic.AddUint64(&s.timeouts, 1)
+		case ErrRateLimited:
+			atomic.AddUint64(&s.rateLimited, 1)
+		}
+		s.addError(err.Code)
+	case Error:
+		s.addError(err.Code)
+	default:
+		atomic.AddUint64(&s.networkErrors, 1)
+	}
+}
+
+func (s *connStats) addError(code uint32) {
+	s.errorsMutex.Lock()
+	s.errors[code]++
+	s.errorsMutex.Unlock()
+}
+
+func (l *latencyStats) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	atomic.AddUint64(&l.counts[i], 1)
+	atomic.AddUint64(&l.count, 1)
+	atomic.AddUint64(&l.sum, uint64(d))
+}
+
+// Stats returns snapshot of connection statistics.
+func (conn *Connection) Stats() Stats {
+	s := conn.stats
+	stats := Stats{
+		Requests:            make(map[int32]uint64),
+		Responses:           atomic.LoadUint64(&s.responses),
+		UnexpectedResponses: atomic.LoadUint64(&s.unexpected),
+		Errors:              make(map[uint32]uint64),
+		NetworkErrors:       atomic.LoadUint64(&s.networkErrors),
+		Timeouts:            atomic.LoadUint64(&s.timeouts),
+		RateLimited:         atomic.LoadUint64(&s.rateLimited),
+		Reconnects:          atomic.LoadUint64(&s.reconnects),
+		BytesRead:           atomic.LoadUint64(&s.bytesRead),
+		BytesWritten:        atomic.LoadUint64(&s.bytesWritten),
+		InFlight:            make([]int, len(conn.shard)),
+		Latency:             make(map[int32]LatencyHistogram),
+	}
+	for code := range s.requests {
+		if n := atomic.LoadUint64(&s.requests[code]); n > 0 {
+			stats.Requests[int32(code)] = n
+		}
+	}
+	s.errorsMutex.Lock()
+	for code, n := range s.errors {
+		stats.Errors[code] = n
+	}
+	s.errorsMutex.Unlock()
+	for code := range s.latency {
+		l := &s.latency[code]
+		if atomic.LoadUint64(&l.count) == 0 {
+			continue
+		}
+		h := LatencyHistogram{
+			Bounds: append([]time.Duration(nil), latencyBounds[:]...),
+			Counts: make([]uint64, len(l.counts)),
+			Sum:    time.Duration(atomic.LoadUint64(&l.sum)),
+		}
+		// Count is a sum of buckets to keep snapshot consistent.
+		for i := range l.counts {
+			h.Counts[i] = atomic.LoadUint64(&l.counts[i])
+			h.Count += h.Counts[i]
+		}
+		stats.Latency[int32(code)] = h
+	}
+	for i := range conn.shard {
+		shard := &conn.shard[i]
+		shard.rmut.Lock()
+		for pos := range shard.requests {
+			for fut := shard.requests[pos].first; fut != nil; fut = fut.next {
+				stats.InFlight[i]++
+			}
+		}
+		shard.rmut.Unlock()
+	}
+	return stats
+}
+
+// InFlight returns number of requests waiting for response. It is cheaper
+// than Stats.
+func (conn *Connection) InFlight() int {
+	return int(atomic.LoadInt64(&conn.stats.inFlight))
+}
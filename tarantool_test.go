@@ -1,9 +1,13 @@
 package tarantool_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -876,6 +880,87 @@ func TestSchema(t *testing.T) {
 	}
 }
 
+func TestSchemaExt(t *testing.T) {
+	var err error
+	var conn *Connection
+
+	conn, err = Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if conn == nil {
+		t.Fatalf("conn is nil after Connect")
+	}
+	defer conn.Close()
+
+	schema := conn.GetSchema()
+	space, ok := schema.Spaces["schematest_ext"]
+	if !ok {
+		t.Fatalf("space schematest_ext is not loaded")
+	}
+	field, ok := space.Fields["name"]
+	if !ok {
+		t.Fatalf("field name is not loaded")
+	}
+	if !field.IsNullable {
+		t.Errorf("field name should be nullable")
+	}
+	if field.Collation != "unicode_ci" {
+		t.Errorf("field name has collation %q, expected unicode_ci", field.Collation)
+	}
+	if space.Fields["id"].IsNullable {
+		t.Errorf("field id should not be nullable")
+	}
+
+	index, ok := space.Indexes["name"]
+	if !ok {
+		t.Fatalf("index name is not loaded")
+	}
+	if len(index.Fields) != 1 {
+		t.Fatalf("index name has %d parts, expected 1", len(index.Fields))
+	}
+	part := index.Fields[0]
+	if part.Id != 1 || part.Type != "string" || !part.IsNullable || part.Collation != "unicode_ci" {
+		t.Errorf("unexpected index part %+v", part)
+	}
+	if index.Unique {
+		t.Errorf("index name should not be unique")
+	}
+	if unique, ok := index.Opts["unique"].(bool); !ok || unique {
+		t.Errorf("unexpected index opts %v", index.Opts)
+	}
+
+	seq, ok := schema.Sequences["test_seq"]
+	if !ok {
+		t.Fatalf("sequence test_seq is not loaded")
+	}
+	if seq.Start != 10 || seq.Step != 2 || seq.Cycle {
+		t.Errorf("unexpected sequence %+v", seq)
+	}
+	if schema.SequencesById[seq.Id] != seq {
+		t.Errorf("sequence is not accessible by id")
+	}
+
+	fn, ok := schema.Functions["simple_incr"]
+	if !ok {
+		t.Fatalf("function simple_incr is not loaded")
+	}
+	if fn.Language != "LUA" {
+		t.Errorf("unexpected function language %s", fn.Language)
+	}
+	if schema.FunctionsById[fn.Id] != fn {
+		t.Errorf("function is not accessible by id")
+	}
+
+	// Nil key part is accepted only for nullable index part.
+	if NewIndexKey(index).Nil().Err() != nil {
+		t.Errorf("nil is not accepted for nullable part")
+	}
+	if NewIndexKey(space.Indexes["primary"]).Nil().Err() == nil {
+		t.Errorf("nil is accepted for not nullable part")
+	}
+}
+
 func TestClientNamed(t *testing.T) {
 	var resp *Response
 	var err error
@@ -1009,6 +1094,796 @@ func TestComplexStructs(t *testing.T) {
 	}
 }
 
+func TestOperations(t *testing.T) {
+	var err error
+	var conn *Connection
+
+	conn, err = Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if conn == nil {
+		t.Fatalf("conn is nil after Connect")
+	}
+	defer conn.Close()
+
+	_, err = conn.Replace(spaceNo, []interface{}{uint(1020), "hello", "world", uint(6)})
+	if err != nil {
+		t.Fatalf("Failed to Replace: %s", err.Error())
+	}
+
+	ops := NewOperations().
+		Assign(1, "bye").
+		Splice(2, 1, 1, "W").
+		Add(3, 4).
+		BitwiseOr(3, 1)
+	var tpl []interface{}
+	err = conn.UpdateTyped(spaceNo, indexNo, UintKey{1020}, ops, &tpl)
+	if err != nil {
+		t.Fatalf("Failed to Update: %s", err.Error())
+	}
+	if len(tpl) != 1 {
+		t.Fatalf("Result len of Update != 1")
+	}
+	if res := tpl[0].([]interface{}); res[1] != "bye" || res[2] != "World" || res[3] != uint64(11) {
+		t.Errorf("Unexpected tuple after Update: %v", res)
+	}
+
+	ops = NewOperations().Delete(3, 1).Insert(1, "hi")
+	err = conn.UpdateTyped(spaceNo, indexNo, UintKey{1020}, ops, &tpl)
+	if err != nil {
+		t.Fatalf("Failed to Update: %s", err.Error())
+	}
+	if res := tpl[0].([]interface{}); len(res) != 4 || res[1] != "hi" || res[2] != "bye" {
+		t.Errorf("Unexpected tuple after Update: %v", res)
+	}
+
+	ops = NewOperations().Add(1, 1)
+	_, err = conn.Upsert(spaceNo, []interface{}{uint(1021), 1}, ops)
+	if err != nil {
+		t.Errorf("Failed to Upsert: %s", err.Error())
+	}
+
+	// Builder errors
+	ops = NewOperations().Add(1, "one")
+	if ops.Err() == nil {
+		t.Errorf("Non-numeric argument of '+' is accepted")
+	}
+	if _, err = conn.Update(spaceNo, indexNo, UintKey{1020}, ops); err == nil {
+		t.Errorf("Update with invalid operations succeeded")
+	}
+	if NewOperations().Assign(1.5, 1).Err() == nil {
+		t.Errorf("Float field number is accepted")
+	}
+	if NewOperations().Delete(1, 0).Err() == nil {
+		t.Errorf("Zero count of deleted fields is accepted")
+	}
+
+	// Validation against space format
+	ops = NewOperations().Splice("name1", 1, 1, "a")
+	if _, err = conn.Update("schematest", "primary", UintKey{1}, ops); err == nil {
+		t.Errorf("Splice of unsigned field succeeded")
+	}
+	ops = NewOperations().BitwiseAnd("name2", 1)
+	if err = ops.Validate(conn.GetSchema().Spaces["schematest"]); err == nil {
+		t.Errorf("Bitwise operation on string field is valid")
+	}
+	ops = NewOperations().Assign("unknown", 1)
+	if err = ops.Validate(conn.GetSchema().Spaces["schematest"]); err == nil {
+		t.Errorf("Operation on unknown field is valid")
+	}
+	ops = NewOperations().Assign("name5.a[1]", 1).Add(4, 1).Add(-1, 1)
+	if err = ops.Validate(conn.GetSchema().Spaces["schematest"]); err != nil {
+		t.Errorf("Valid operations are rejected: %s", err.Error())
+	}
+}
+
+func TestKey(t *testing.T) {
+	var err error
+	var conn *Connection
+
+	conn, err = Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if conn == nil {
+		t.Fatalf("conn is nil after Connect")
+	}
+	defer conn.Close()
+
+	_, err = conn.Replace("schematest", []interface{}{uint(1), uint(2), "key", uint(3)})
+	if err != nil {
+		t.Fatalf("Failed to Replace: %s", err.Error())
+	}
+
+	key := NewKey().Uint(2).Str("key")
+	if key.Len() != 2 {
+		t.Errorf("Unexpected key length %d", key.Len())
+	}
+	resp, err := conn.Select("schematest", "secondary", 0, 1, IterEq, key)
+	if err != nil {
+		t.Fatalf("Failed to Select: %s", err.Error())
+	}
+	if len(resp.Data) != 1 {
+		t.Errorf("Response Data len != 1")
+	}
+
+	key, err = conn.GetSchema().IndexKey("schematest", "secondary")
+	if err != nil {
+		t.Fatalf("Failed to create index key: %s", err.Error())
+	}
+	key.Value(2).Value("key")
+	if key.Err() != nil {
+		t.Fatalf("Failed to build index key: %s", key.Err().Error())
+	}
+	resp, err = conn.Select("schematest", "secondary", 0, 1, IterEq, key)
+	if err != nil {
+		t.Fatalf("Failed to Select: %s", err.Error())
+	}
+	if len(resp.Data) != 1 {
+		t.Errorf("Response Data len != 1")
+	}
+
+	key.Reset()
+	if key.Str("key").Err() == nil {
+		t.Errorf("String part is accepted for unsigned index part")
+	}
+	key.Reset()
+	if key.Uint(2).Str("key").Uint(3).Err() == nil {
+		t.Errorf("Excess part is accepted")
+	}
+	key.Reset()
+	if key.Int(-1).Err() == nil {
+		t.Errorf("Negative part is accepted for unsigned index part")
+	}
+	if _, err = conn.Select("schematest", "secondary", 0, 1, IterEq, key); err == nil {
+		t.Errorf("Select with invalid key succeeded")
+	}
+	if _, err = conn.GetSchema().IndexKey("schematest", "unknown"); err == nil {
+		t.Errorf("Key for unknown index is created")
+	}
+}
+
+func TestSpaceHandles(t *testing.T) {
+	var err error
+	var conn *Connection
+
+	conn, err = Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if conn == nil {
+		t.Fatalf("conn is nil after Connect")
+	}
+	defer conn.Close()
+
+	space := conn.Space(spaceName)
+	if id, err := space.Id(); err != nil || id != spaceNo {
+		t.Errorf("Unexpected space id %d: %v", id, err)
+	}
+	for i := 1030; i < 1033; i++ {
+		if _, err = space.Replace([]interface{}{uint(i), "hello", "world"}); err != nil {
+			t.Fatalf("Failed to Replace: %s", err.Error())
+		}
+	}
+	if _, err = space.Insert([]interface{}{uint(1030), "hello", "world"}); err == nil {
+		t.Errorf("Duplicate Insert succeeded")
+	}
+	if _, err = space.Update(UintKey{1031}, NewOperations().Assign(1, "bye")); err != nil {
+		t.Errorf("Failed to Update: %s", err.Error())
+	}
+
+	index := space.Index(indexName)
+	var tpl Tuple
+	if err = index.Get(UintKey{1031}, &tpl); err != nil {
+		t.Errorf("Failed to Get: %s", err.Error())
+	}
+	if tpl.Id != 1031 || tpl.Msg != "bye" {
+		t.Errorf("Unexpected tuple: %v", tpl)
+	}
+	if err = index.Min(UintKey{1030}, &tpl); err != nil || tpl.Id != 1030 {
+		t.Errorf("Unexpected Min: %v, %v", tpl, err)
+	}
+	if err = index.Max(UintKey{1032}, &tpl); err != nil || tpl.Id != 1032 {
+		t.Errorf("Unexpected Max: %v, %v", tpl, err)
+	}
+	count, err := index.Count(IterGe, UintKey{1030})
+	if err != nil {
+		t.Errorf("Failed to Count: %s", err.Error())
+	}
+	if count < 3 {
+		t.Errorf("Unexpected Count: %d", count)
+	}
+	if _, err = space.Delete(UintKey{1032}); err != nil {
+		t.Errorf("Failed to Delete: %s", err.Error())
+	}
+
+	// Handles are re-resolved after schema reload.
+	if err = conn.ReloadSchema(); err != nil {
+		t.Fatalf("Failed to reload schema: %s", err.Error())
+	}
+	var tpls []Tuple
+	if err = index.SelectTyped(0, 10, IterEq, UintKey{1030}, &tpls); err != nil || len(tpls) != 1 {
+		t.Errorf("Unexpected Select after reload: %v, %v", tpls, err)
+	}
+
+	// Cached ids are changed after space is recreated.
+	recreate := func(id uint32) uint32 {
+		var ids []uint32
+		if err := conn.Call17Typed("recreate_space", []interface{}{"handles_test", id}, &ids); err != nil || len(ids) != 1 {
+			t.Fatalf("Failed to recreate space: %v, %v", ids, err)
+		}
+		if err := conn.ReloadSchema(); err != nil {
+			t.Fatalf("Failed to reload schema: %s", err.Error())
+		}
+		return ids[0]
+	}
+	recreated := conn.Space("handles_test")
+	oldId := recreate(600)
+	if id, err := recreated.Id(); err != nil || id != oldId {
+		t.Errorf("Unexpected id of created space %d, expected %d: %v", id, oldId, err)
+	}
+	newId := recreate(601)
+	if newId == oldId {
+		t.Fatalf("Space is recreated with the same id %d", newId)
+	}
+	if id, err := recreated.Id(); err != nil || id != newId {
+		t.Errorf("Unexpected id of recreated space %d, expected %d: %v", id, newId, err)
+	}
+
+	if _, err = conn.Space("unknown").Insert([]interface{}{1}); err == nil {
+		t.Errorf("Insert into unknown space succeeded")
+	}
+	if _, err = space.Index("unknown").Select(0, 1, IterAll, []interface{}{}); err == nil {
+		t.Errorf("Select by unknown index succeeded")
+	}
+}
+
+func TestStats(t *testing.T) {
+	var err error
+	var conn *Connection
+
+	conn, err = Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if conn == nil {
+		t.Fatalf("conn is nil after Connect")
+	}
+	defer conn.Close()
+
+	// pinger sends pings in background, so counters are checked as lower bounds
+	before := conn.Stats()
+	for i := 0; i < 3; i++ {
+		if _, err = conn.Ping(); err != nil {
+			t.Fatalf("Failed to Ping: %s", err.Error())
+		}
+	}
+	if _, err = conn.Replace(spaceNo, []interface{}{uint(1040), "hello", "world"}); err != nil {
+		t.Fatalf("Failed to Replace: %s", err.Error())
+	}
+	if _, err = conn.Insert(spaceNo, []interface{}{uint(1040), "hello", "world"}); err == nil {
+		t.Fatalf("Insert into existing key did not fail")
+	}
+	if _, err = conn.Call17("simple_incr", []interface{}{1}); err != nil {
+		t.Fatalf("Failed to Call17: %s", err.Error())
+	}
+
+	stats := conn.Stats()
+	if n := stats.Requests[PingRequest] - before.Requests[PingRequest]; n < 3 {
+		t.Errorf("Unexpected Ping requests count: %d", n)
+	}
+	if n := stats.Requests[InsertRequest] - before.Requests[InsertRequest]; n != 1 {
+		t.Errorf("Unexpected Insert requests count: %d", n)
+	}
+	if n := stats.Responses - before.Responses; n < 6 {
+		t.Errorf("Unexpected responses count: %d", n)
+	}
+	if n := stats.Errors[ErrTupleFound] - before.Errors[ErrTupleFound]; n != 1 {
+		t.Errorf("Unexpected ErrTupleFound count: %d", n)
+	}
+	if stats.BytesRead <= before.BytesRead || stats.BytesWritten <= before.BytesWritten {
+		t.Errorf("Bytes counters are not increased: %+v", stats)
+	}
+	if len(stats.InFlight) == 0 {
+		t.Errorf("Unexpected in-flight requests: %v", stats.InFlight)
+	}
+	h := stats.Latency[PingRequest]
+	if h.Count < 3 || len(h.Counts) != len(h.Bounds)+1 || h.Sum <= 0 {
+		t.Errorf("Unexpected Ping latency histogram: %+v", h)
+	}
+	var sum uint64
+	for _, n := range h.Counts {
+		sum += n
+	}
+	if sum != h.Count {
+		t.Errorf("Histogram buckets sum %d != count %d", sum, h.Count)
+	}
+
+	// timeouted request
+	fut := conn.EvalAsync("require('fiber').sleep(1)", []interface{}{})
+	if conn.Stats().InFlightTotal() < 1 {
+		t.Errorf("Unexpected in-flight requests: %v", conn.Stats().InFlight)
+	}
+	if conn.InFlight() < 1 {
+		t.Errorf("Unexpected in-flight requests: %d", conn.InFlight())
+	}
+	if _, err = fut.Get(); err == nil {
+		t.Fatalf("Request was not timeouted")
+	}
+	if conn.InFlight() != 0 {
+		t.Errorf("Unexpected in-flight requests after timeout: %d", conn.InFlight())
+	}
+	if n := conn.Stats().Timeouts - before.Timeouts; n != 1 {
+		t.Errorf("Unexpected timeouts count: %d", n)
+	}
+	if n := conn.Stats().Errors[ErrTimeouted] - before.Errors[ErrTimeouted]; n != 1 {
+		t.Errorf("Unexpected ErrTimeouted count: %d", n)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	var mutex sync.Mutex
+	var calls []string
+	var infos []RequestInfo
+	errForbidden := errors.New("eval is forbidden")
+
+	interceptorOpts := opts
+	interceptorOpts.Interceptors = []Interceptor{
+		{
+			BeforeSend: func(info *RequestInfo) error {
+				if info.Code == PingRequest {
+					// ignore pinger
+					return nil
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "before1")
+				return nil
+			},
+			OnComplete: func(info *RequestInfo, resp *Response, err error, latency time.Duration) {
+				if info.Code == PingRequest {
+					return
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "complete1")
+				infos = append(infos, *info)
+			},
+		},
+		{
+			BeforeSend: func(info *RequestInfo) error {
+				if info.Code == PingRequest {
+					// ignore pinger
+					return nil
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "before2")
+				if info.Code == EvalRequest {
+					return errForbidden
+				}
+				return nil
+			},
+			OnComplete: func(info *RequestInfo, resp *Response, err error, latency time.Duration) {
+				if info.Code == PingRequest {
+					return
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "complete2")
+				if info.Code != EvalRequest && (resp == nil || err != nil || latency <= 0) {
+					t.Errorf("Unexpected completion of %d: %v, %v, %v", info.Code, resp, err, latency)
+				}
+			},
+		},
+	}
+
+	conn, err := Connect(server, interceptorOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	reset := func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = nil
+		infos = nil
+	}
+	check := func(expected ...string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !reflect.DeepEqual(calls, expected) {
+			t.Errorf("Unexpected interceptor calls: %v, expected: %v", calls, expected)
+		}
+	}
+
+	reset()
+	if _, err = conn.Select(spaceName, indexName, 0, 1, IterEq, []interface{}{uint(10)}); err != nil {
+		t.Fatalf("Failed to Select: %s", err.Error())
+	}
+	check("before1", "before2", "complete2", "complete1")
+	mutex.Lock()
+	info := infos[0]
+	mutex.Unlock()
+	if info.Code != SelectRequest || info.Space != spaceName || info.Index != indexName ||
+		info.SpaceNo != spaceNo || info.IndexNo != indexNo || info.Size == 0 || info.Addr != server {
+		t.Errorf("Unexpected request info: %+v", info)
+	}
+
+	reset()
+	if _, err = conn.Call17("simple_incr", []interface{}{1}); err != nil {
+		t.Fatalf("Failed to Call17: %s", err.Error())
+	}
+	mutex.Lock()
+	if infos[0].FunctionName != "simple_incr" {
+		t.Errorf("Unexpected request info: %+v", infos[0])
+	}
+	mutex.Unlock()
+
+	// request is short-circuited by second interceptor
+	reset()
+	if _, err = conn.Eval("return 1", []interface{}{}); err != errForbidden {
+		t.Errorf("Unexpected Eval error: %v", err)
+	}
+	check("before1", "before2", "complete2", "complete1")
+	if n := conn.Stats().Requests[EvalRequest]; n != 0 {
+		t.Errorf("Rejected request was sent: %d", n)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	type ctxKey struct{}
+	infos := make(chan RequestInfo, 10)
+
+	interceptorOpts := opts
+	interceptorOpts.Interceptors = []Interceptor{{
+		OnComplete: func(info *RequestInfo, resp *Response, err error, latency time.Duration) {
+			if info.Code != PingRequest {
+				infos <- *info
+			}
+		},
+	}}
+	conn, err := Connect(server, interceptorOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	scoped := conn.WithContext(ctx)
+	if _, err = scoped.Select(spaceNo, indexNo, 0, 1, IterEq, []interface{}{uint(10)}); err != nil {
+		t.Fatalf("Failed to Select: %s", err.Error())
+	}
+	info := <-infos
+	if info.Context.Value(ctxKey{}) != "value" {
+		t.Errorf("Context is not passed to interceptor")
+	}
+	if info.SpaceName != spaceName || info.IndexName != indexName {
+		t.Errorf("Unexpected space and index names: %q, %q", info.SpaceName, info.IndexName)
+	}
+	if info.RemoteAddr != conn.RemoteAddr() {
+		t.Errorf("Unexpected remote address: %q", info.RemoteAddr)
+	}
+
+	if _, err = conn.Eval("return 1", []interface{}{}); err != nil {
+		t.Fatalf("Failed to Eval: %s", err.Error())
+	}
+	info = <-infos
+	if info.Context == nil || info.Context.Value(ctxKey{}) != nil {
+		t.Errorf("Unexpected context of unscoped request")
+	}
+	if info.Expression != "return 1" {
+		t.Errorf("Unexpected expression: %q", info.Expression)
+	}
+}
+
+type eventsLogger struct {
+	mutex  sync.Mutex
+	events []ConnLogKind
+}
+
+func (l *eventsLogger) Log(level LogLevel, event ConnLogKind, conn *Connection, msg string, fields ...LogField) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventsLogger) has(event ConnLogKind) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, e := range l.events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStructuredLogger(t *testing.T) {
+	logger := &eventsLogger{}
+	loggerOpts := opts
+	loggerOpts.StructuredLogger = logger
+
+	conn, err := Connect(server, loggerOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	if err = conn.ReloadSchema(); err != nil {
+		t.Fatalf("Failed to reload schema: %s", err.Error())
+	}
+	conn.Close()
+
+	for _, event := range []ConnLogKind{LogConnected, LogAuthenticated, LogSchemaLoaded, LogClosed} {
+		if !logger.has(event) {
+			t.Errorf("Event %s is not logged: %v", event, logger.events)
+		}
+	}
+}
+
+func TestRequestRate(t *testing.T) {
+	rateOpts := opts
+	rateOpts.RLimitAction = RLimitDrop
+	rateOpts.RequestRateByCode = map[int32]RequestRate{
+		EvalRequest: {PerSecond: 1, Burst: 2},
+	}
+	conn, err := Connect(server, rateOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err = conn.Eval("return 1", []interface{}{}); err != nil {
+			t.Fatalf("Failed to Eval: %s", err.Error())
+		}
+	}
+	_, err = conn.Eval("return 1", []interface{}{})
+	if cerr, ok := err.(ClientError); !ok || cerr.Code != ErrRateLimited {
+		t.Errorf("Expected ErrRateLimited, got: %v", err)
+	}
+	// other requests are not limited
+	for i := 0; i < 5; i++ {
+		if _, err = conn.Select(spaceNo, indexNo, 0, 1, IterEq, []interface{}{uint(10)}); err != nil {
+			t.Fatalf("Failed to Select: %s", err.Error())
+		}
+	}
+	if n := conn.Stats().RateLimited; n != 1 {
+		t.Errorf("Unexpected rate limited requests count: %d", n)
+	}
+}
+
+func TestRequestRateInternal(t *testing.T) {
+	rateOpts := opts
+	rateOpts.RLimitAction = RLimitDrop
+	rateOpts.RequestRate = RequestRate{PerSecond: 1, Burst: 1}
+	conn, err := Connect(server, rateOpts)
+	if err != nil {
+		t.Fatalf("Schema loading is rate limited: %s", err.Error())
+	}
+	defer conn.Close()
+
+	if err = conn.ReloadSchema(); err != nil {
+		t.Errorf("Schema reloading is rate limited: %s", err.Error())
+	}
+	// schema loading does not spend request rate
+	if _, err = conn.Select(spaceNo, indexNo, 0, 1, IterEq, []interface{}{uint(10)}); err != nil {
+		t.Errorf("Failed to Select: %s", err.Error())
+	}
+}
+
+func TestRequestRateWait(t *testing.T) {
+	rateOpts := opts
+	rateOpts.RLimitAction = RLimitWait
+	rateOpts.RequestRate = RequestRate{PerSecond: 20, Burst: 1}
+	rateOpts.RequestRateByCode = map[int32]RequestRate{
+		// token is not got during timeout
+		CallRequest: {PerSecond: 1, Burst: 1},
+	}
+	conn, err := Connect(server, rateOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err = conn.Eval("return 1", []interface{}{}); err != nil {
+			t.Fatalf("Failed to Eval: %s", err.Error())
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Requests are not delayed: %s", elapsed)
+	}
+
+	if _, err = conn.Call("simple_incr", []interface{}{1}); err != nil {
+		t.Fatalf("Failed to Call: %s", err.Error())
+	}
+	_, err = conn.Call("simple_incr", []interface{}{1})
+	if cerr, ok := err.(ClientError); !ok || cerr.Code != ErrRateLimited {
+		t.Errorf("Expected ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	conn, err := Connect(server, opts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	isTimeout := func(err error) bool {
+		cerr, ok := err.(ClientError)
+		return ok && cerr.Code == ErrTimeouted
+	}
+	sleep := "require('fiber').sleep(...)"
+
+	// longer than opts.Timeout
+	if _, err := conn.WithTimeout(2*time.Second).Eval(sleep, []interface{}{0.7}); err != nil {
+		t.Errorf("Failed to Eval with long timeout: %s", err.Error())
+	}
+
+	start := time.Now()
+	_, err = conn.WithTimeout(50*time.Millisecond).Eval(sleep, []interface{}{0.3})
+	if !isTimeout(err) {
+		t.Errorf("Expected ErrTimeouted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Request is timeouted too late: %s", elapsed)
+	}
+
+	// short timeout is not delayed by pending requests with long timeout
+	long := conn.WithTimeout(3*time.Second).EvalAsync(sleep, []interface{}{1})
+	start = time.Now()
+	_, err = conn.WithDeadline(start.Add(50*time.Millisecond)).Eval(sleep, []interface{}{0.3})
+	if !isTimeout(err) {
+		t.Errorf("Expected ErrTimeouted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Request is timeouted too late: %s", elapsed)
+	}
+	if _, err = long.Get(); err != nil {
+		t.Errorf("Failed to Eval with long timeout: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = conn.WithContext(ctx).Eval(sleep, []interface{}{0.3}); !isTimeout(err) {
+		t.Errorf("Expected ErrTimeouted by context deadline, got: %v", err)
+	}
+
+	_, err = conn.WithDeadline(time.Now().Add(-time.Second)).Ping()
+	if !isTimeout(err) {
+		t.Errorf("Expected ErrTimeouted for exceeded deadline, got: %v", err)
+	}
+
+	if timeout := conn.WithTimeout(time.Minute).ConfiguredTimeout(); timeout != time.Minute {
+		t.Errorf("Unexpected configured timeout: %s", timeout)
+	}
+}
+
+func TestSocketOptions(t *testing.T) {
+	var configured net.Conn
+	sockOpts := opts
+	sockOpts.DialTimeout = time.Second
+	sockOpts.KeepAlive = 10 * time.Second
+	sockOpts.ReadBufferSize = 4 * 1024
+	sockOpts.WriteBufferSize = 4 * 1024
+	sockOpts.ConfigureSocket = func(c net.Conn) error {
+		configured = c
+		if tcp, ok := c.(*net.TCPConn); ok {
+			return tcp.SetNoDelay(false)
+		}
+		return nil
+	}
+	conn, err := Connect(server, sockOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+	if _, ok := configured.(*net.TCPConn); !ok {
+		t.Errorf("ConfigureSocket is not called with tcp connection: %#v", configured)
+	}
+	// response is larger than buffers
+	big := strings.Repeat("x", 16*1024)
+	resp, err := conn.Eval("return ...", []interface{}{big})
+	if err != nil {
+		t.Fatalf("Failed to Eval: %s", err.Error())
+	}
+	if len(resp.Data) != 1 || resp.Data[0] != big {
+		t.Errorf("Unexpected response of Eval")
+	}
+
+	sockErr := errors.New("socket is not configured")
+	sockOpts.ConfigureSocket = func(c net.Conn) error {
+		return sockErr
+	}
+	if _, err = Connect(server, sockOpts); err != sockErr {
+		t.Errorf("Expected ConfigureSocket error, got: %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	retryOpts := opts
+	retryOpts.Retry = RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     ConstantBackoff{10 * time.Millisecond},
+	}
+	conn, err := Connect(server, retryOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	defer conn.Close()
+
+	// fails with transaction conflict until counter reaches limit
+	expr := `
+		local name, limit = ...
+		local cnt = (rawget(_G, name) or 0) + 1
+		rawset(_G, name, cnt)
+		if cnt < limit then
+			box.error({code = 97, reason = "conflict"})
+		end
+		return cnt
+	`
+
+	resp, err := conn.Idempotent().Eval(expr, []interface{}{"retry_ok", 3})
+	if err != nil {
+		t.Fatalf("Idempotent request is not retried: %s", err.Error())
+	}
+	if len(resp.Data) != 1 || fmt.Sprint(resp.Data[0]) != "3" {
+		t.Errorf("Unexpected response: %v", resp.Data)
+	}
+
+	_, err = conn.Idempotent().Eval(expr, []interface{}{"retry_exhausted", 5})
+	if terr, ok := err.(Error); !ok || terr.Code != ErrTransactionConflict {
+		t.Errorf("Expected ErrTransactionConflict after last attempt, got: %v", err)
+	}
+
+	// not idempotent request is not retried
+	_, err = conn.Eval(expr, []interface{}{"retry_none", 2})
+	if terr, ok := err.(Error); !ok || terr.Code != ErrTransactionConflict {
+		t.Errorf("Expected ErrTransactionConflict, got: %v", err)
+	}
+
+	var tuples []Tuple
+	if err = conn.SelectTyped(spaceNo, indexNo, 0, 1, IterAll, []interface{}{}, &tuples); err != nil {
+		t.Errorf("Failed to SelectTyped with retries: %s", err.Error())
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	var mutex sync.Mutex
+	var kinds []ConnEventKind
+	closed := make(chan struct{})
+	eventOpts := opts
+	eventOpts.OnEvent = func(event ConnEvent) {
+		mutex.Lock()
+		kinds = append(kinds, event.Kind)
+		mutex.Unlock()
+		if event.Kind == Closed {
+			close(closed)
+		}
+	}
+	conn, err := Connect(server, eventOpts)
+	if err != nil {
+		t.Fatalf("Failed to connect: %s", err.Error())
+	}
+	conn.Close()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Closed event is not received")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []ConnEventKind{Connected, SchemaReloaded, Closed}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Unexpected events: %v, expected %v", kinds, expected)
+	}
+}
+
 // runTestMain is a body of TestMain function
 // (see https://pkg.go.dev/testing#hdr-Main).
 // Using defer + os.Exit is not works so TestMain body
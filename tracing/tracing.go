@@ -0,0 +1,185 @@
+// Package tracing starts a span for each request of tarantool Connection.
+//
+// Package does not depend on any tracing library: Tracer and Span interfaces
+// mirror a subset of OpenTelemetry API, so an OpenTelemetry tracer could be
+// adapted with a few lines of code.
+//
+// Tracing is installed as an interceptor, and parent span is taken from the
+// context of request passed with Connection.WithContext:
+//
+//	opts.Interceptors = append(opts.Interceptors, tracing.Interceptor(tracer))
+//	conn, err := tarantool.Connect(addr, opts)
+//	...
+//	resp, err := conn.WithContext(ctx).Select(...)
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// Attribute keys set on spans.
+const (
+	AttrDBSystem    = "db.system"
+	AttrDBOperation = "db.operation"
+	AttrSpace       = "db.tarantool.space"
+	AttrIndex       = "db.tarantool.index"
+	AttrFunction    = "db.tarantool.function"
+	AttrRequestId   = "db.tarantool.request_id"
+	AttrErrorCode   = "db.tarantool.error_code"
+	AttrPeerAddress = "net.peer.name"
+	AttrRequestSize = "db.tarantool.request_size"
+	AttrOutcome     = "db.tarantool.outcome"
+)
+
+// Values of AttrOutcome attribute.
+const (
+	OutcomeOk          = "ok"
+	OutcomeError       = "error"
+	OutcomeTimeout     = "timeout"
+	OutcomeClientError = "client_error"
+)
+
+// StatusCode is a status of finished span.
+type StatusCode int
+
+const (
+	// StatusUnset is a status of span finished without error.
+	StatusUnset StatusCode = iota
+	// StatusError is a status of span finished with error.
+	StatusError
+	// StatusOk is a status of span explicitly marked as successful.
+	StatusOk
+)
+
+// Attribute is a key-value pair describing span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts span as a child of span in ctx and returns context
+	// containing started span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	SetStatus(code StatusCode, description string)
+	End()
+}
+
+// spanKey is a key of context value holding span started by interceptor.
+type spanKey struct{}
+
+// SpanFromContext returns span started for request by Interceptor.
+// It is nil if there is no span in ctx.
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+var operations = map[int32]string{
+	tarantool.SelectRequest:  "select",
+	tarantool.InsertRequest:  "insert",
+	tarantool.ReplaceRequest: "replace",
+	tarantool.UpdateRequest:  "update",
+	tarantool.DeleteRequest:  "delete",
+	tarantool.CallRequest:    "call",
+	tarantool.EvalRequest:    "eval",
+	tarantool.UpsertRequest:  "upsert",
+	tarantool.Call17Request:  "call17",
+	tarantool.PingRequest:    "ping",
+}
+
+func operation(code int32) string {
+	if op, ok := operations[code]; ok {
+		return op
+	}
+	return "unknown"
+}
+
+// spanName returns name of span: operation followed by space or function name.
+func spanName(info *tarantool.RequestInfo) string {
+	name := operation(info.Code)
+	switch {
+	case info.SpaceName != "":
+		name += " " + info.SpaceName
+	case info.FunctionName != "":
+		name += " " + info.FunctionName
+	}
+	return name
+}
+
+// Interceptor returns interceptor, which starts span for each request.
+//
+// Span is started before request is sent and ended on completion. Span has
+// attributes with request code, space and index names, function name,
+// request id, remote address and outcome; failed request is recorded as
+// error.
+func Interceptor(tracer Tracer) tarantool.Interceptor {
+	return tarantool.Interceptor{
+		BeforeSend: func(info *tarantool.RequestInfo) error {
+			attrs := []Attribute{
+				{AttrDBSystem, "tarantool"},
+				{AttrDBOperation, operation(info.Code)},
+				{AttrRequestId, info.RequestId},
+				{AttrRequestSize, info.Size},
+			}
+			if info.RemoteAddr != "" {
+				attrs = append(attrs, Attribute{AttrPeerAddress, info.RemoteAddr})
+			} else {
+				attrs = append(attrs, Attribute{AttrPeerAddress, info.Addr})
+			}
+			if info.SpaceName != "" {
+				attrs = append(attrs, Attribute{AttrSpace, info.SpaceName})
+			} else if info.Space != nil {
+				attrs = append(attrs, Attribute{AttrSpace, info.SpaceNo})
+			}
+			if info.IndexName != "" {
+				attrs = append(attrs, Attribute{AttrIndex, info.IndexName})
+			}
+			if info.FunctionName != "" {
+				attrs = append(attrs, Attribute{AttrFunction, info.FunctionName})
+			}
+			ctx, span := tracer.Start(info.Context, spanName(info), attrs...)
+			info.Context = context.WithValue(ctx, spanKey{}, span)
+			return nil
+		},
+		OnComplete: func(info *tarantool.RequestInfo, resp *tarantool.Response, err error, latency time.Duration) {
+			span := SpanFromContext(info.Context)
+			if span == nil {
+				return
+			}
+			switch {
+			case err != nil:
+				outcome := OutcomeClientError
+				if cerr, ok := err.(tarantool.ClientError); ok {
+					if cerr.Code == tarantool.ErrTimeouted {
+						outcome = OutcomeTimeout
+					}
+					span.SetAttributes(Attribute{AttrErrorCode, cerr.Code})
+				}
+				span.SetAttributes(Attribute{AttrOutcome, outcome})
+				span.RecordError(err)
+				span.SetStatus(StatusError, err.Error())
+			case resp != nil && resp.Code != tarantool.OkCode:
+				span.SetAttributes(
+					Attribute{AttrOutcome, OutcomeError},
+					Attribute{AttrErrorCode, resp.Code &^ tarantool.ErrorCodeBit},
+				)
+				span.SetStatus(StatusError, "tarantool error")
+			default:
+				span.SetAttributes(Attribute{AttrOutcome, OutcomeOk})
+				span.SetStatus(StatusOk, "")
+			}
+			span.End()
+		},
+	}
+}
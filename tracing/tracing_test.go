@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// recorder is an in-memory tracer.
+type recorder struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	errs   []error
+	status StatusCode
+	ended  bool
+}
+
+type recordedKey struct{}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	span := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	span.parent, _ = ctx.Value(recordedKey{}).(*recordedSpan)
+	span.SetAttributes(attrs...)
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, recordedKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) SetStatus(code StatusCode, description string) {
+	s.status = code
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+func TestInterceptorSpan(t *testing.T) {
+	tracer := &recorder{}
+	interceptor := Interceptor(tracer)
+
+	parentCtx, parent := tracer.Start(context.Background(), "parent")
+	info := &tarantool.RequestInfo{
+		Context:    parentCtx,
+		Addr:       "127.0.0.1:3013",
+		RemoteAddr: "127.0.0.1:3013",
+		Code:       tarantool.SelectRequest,
+		RequestId:  42,
+		Space:      "test",
+		Index:      "primary",
+		SpaceNo:    512,
+		SpaceName:  "test",
+		IndexName:  "primary",
+		Size:       30,
+	}
+	if err := interceptor.BeforeSend(info); err != nil {
+		t.Fatalf("BeforeSend failed: %s", err)
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Span is not started")
+	}
+	span := tracer.spans[1]
+	if SpanFromContext(info.Context) != span {
+		t.Errorf("Span is not stored in request context")
+	}
+	interceptor.OnComplete(info, &tarantool.Response{RequestId: 42}, nil, time.Millisecond)
+
+	if span.name != "select test" {
+		t.Errorf("Unexpected span name %q", span.name)
+	}
+	if span.parent != parent {
+		t.Errorf("Span is not a child of parent span")
+	}
+	expected := map[string]interface{}{
+		AttrDBSystem:    "tarantool",
+		AttrDBOperation: "select",
+		AttrSpace:       "test",
+		AttrIndex:       "primary",
+		AttrRequestId:   uint32(42),
+		AttrPeerAddress: "127.0.0.1:3013",
+		AttrRequestSize: 30,
+		AttrOutcome:     OutcomeOk,
+	}
+	for key, value := range expected {
+		if span.attrs[key] != value {
+			t.Errorf("Unexpected attribute %s: %v, expected %v", key, span.attrs[key], value)
+		}
+	}
+	if !span.ended || span.status != StatusOk {
+		t.Errorf("Span is not finished successfully: %+v", span)
+	}
+}
+
+func TestInterceptorErrors(t *testing.T) {
+	tracer := &recorder{}
+	interceptor := Interceptor(tracer)
+
+	cases := []struct {
+		info    tarantool.RequestInfo
+		resp    *tarantool.Response
+		err     error
+		name    string
+		outcome string
+		code    interface{}
+	}{
+		{
+			info:    tarantool.RequestInfo{Code: tarantool.Call17Request, FunctionName: "box.info"},
+			resp:    &tarantool.Response{Code: tarantool.ErrorCodeBit | tarantool.ErrNoSuchProc},
+			name:    "call17 box.info",
+			outcome: OutcomeError,
+			code:    uint32(tarantool.ErrNoSuchProc),
+		},
+		{
+			info:    tarantool.RequestInfo{Code: tarantool.EvalRequest, Expression: "return 1"},
+			err:     tarantool.ClientError{Code: tarantool.ErrTimeouted, Msg: "client timeout"},
+			name:    "eval",
+			outcome: OutcomeTimeout,
+			code:    uint32(tarantool.ErrTimeouted),
+		},
+		{
+			info:    tarantool.RequestInfo{Code: tarantool.PingRequest},
+			err:     errors.New("rejected"),
+			name:    "ping",
+			outcome: OutcomeClientError,
+		},
+	}
+	for _, c := range cases {
+		info := c.info
+		info.Context = context.Background()
+		if err := interceptor.BeforeSend(&info); err != nil {
+			t.Fatalf("BeforeSend failed: %s", err)
+		}
+		interceptor.OnComplete(&info, c.resp, c.err, 0)
+		span := tracer.spans[len(tracer.spans)-1]
+		if span.name != c.name {
+			t.Errorf("Unexpected span name %q, expected %q", span.name, c.name)
+		}
+		if span.attrs[AttrOutcome] != c.outcome || span.attrs[AttrErrorCode] != c.code {
+			t.Errorf("Unexpected span attributes of %q: %v", c.name, span.attrs)
+		}
+		if span.status != StatusError || !span.ended {
+			t.Errorf("Span %q is not finished with error", c.name)
+		}
+		if c.err != nil && (len(span.errs) != 1 || span.errs[0] != c.err) {
+			t.Errorf("Error is not recorded on span %q: %v", c.name, span.errs)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package vshard
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strconv"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// DefaultBucketCount is a default total number of buckets in vshard.
+const DefaultBucketCount = 3000
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// digestCRC32 is a checksum computed by Tarantool digest.crc32: CRC32-C
+// without final inversion.
+func digestCRC32(data []byte) uint32 {
+	return ^crc32.Checksum(data, castagnoli)
+}
+
+// luaString returns value converted to string as Lua tostring does for
+// strings and numbers.
+func luaString(key interface{}) string {
+	switch v := key.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float32:
+		return luaFloat(float64(v))
+	case float64:
+		return luaFloat(v)
+	}
+	return fmt.Sprint(key)
+}
+
+func luaFloat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		// LuaJIT prints integral numbers without fraction
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 14, 64)
+}
+
+// BucketIDStrCRC32 returns bucket id of key as
+// vshard.router.bucket_id_strcrc32 does: key is converted to string.
+func BucketIDStrCRC32(key interface{}, total uint64) uint64 {
+	return uint64(digestCRC32([]byte(luaString(key))))%total + 1
+}
+
+// BucketIDMpCRC32 returns bucket id of key as
+// vshard.router.bucket_id_mpcrc32 does: key (or each part of slice key) is
+// encoded with MessagePack.
+func BucketIDMpCRC32(key interface{}, total uint64) (uint64, error) {
+	var data []byte
+	if parts, ok := key.([]interface{}); ok {
+		for _, part := range parts {
+			b, err := msgpack.Marshal(part)
+			if err != nil {
+				return 0, err
+			}
+			data = append(data, b...)
+		}
+	} else {
+		b, err := msgpack.Marshal(key)
+		if err != nil {
+			return 0, err
+		}
+		data = b
+	}
+	return uint64(digestCRC32(data))%total + 1, nil
+}
@@ -0,0 +1,404 @@
+package vshard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tarantool/go-tarantool"
+	"github.com/tarantool/go-tarantool/multi"
+)
+
+var (
+	ErrEmptyReplicasets = errors.New("replicasets should not be empty")
+	ErrWrongBucketID    = errors.New("wrong bucket id")
+	ErrNoRoute          = errors.New("bucket is not found on any replicaset")
+	ErrClosed           = errors.New("router is closed")
+)
+
+// Names of vshard errors handled by router.
+const (
+	ErrWrongBucket          = "WRONG_BUCKET"
+	ErrBucketIsLocked       = "BUCKET_IS_LOCKED"
+	ErrTransferIsInProgress = "TRANSFER_IS_IN_PROGRESS"
+)
+
+const (
+	defaultDiscoveryInterval = 10 * time.Second
+	defaultMaxRetries        = 3
+	defaultRetryDelay        = 100 * time.Millisecond
+)
+
+// Config is a configuration of router.
+type Config struct {
+	// Replicasets maps replicaset UUID to addresses of its instances.
+	Replicasets map[string][]string
+	// TotalBucketCount is a number of buckets in cluster,
+	// DefaultBucketCount by default.
+	TotalBucketCount uint64
+	// ConnOpts are options of connections to instances.
+	ConnOpts tarantool.Opts
+	// PoolOpts are options of replicaset pools. CheckTimeout is 1s by
+	// default.
+	PoolOpts multi.OptsMulti
+	// DiscoveryInterval is a period of discovery of buckets, 10s by default.
+	DiscoveryInterval time.Duration
+	// MaxRetries is a maximum number of retries of call failed because
+	// bucket is moved or locked, 3 by default.
+	MaxRetries int
+	// RetryDelay is a pause before retry of call to locked bucket, 100ms by
+	// default.
+	RetryDelay time.Duration
+}
+
+// StorageError is an error returned by vshard storage.
+type StorageError struct {
+	Type        string
+	Name        string
+	Code        uint64
+	Message     string
+	BucketID    uint64
+	Destination string
+}
+
+// Error converts StorageError to string.
+func (e StorageError) Error() string {
+	if e.Name == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Name)
+}
+
+// Router routes calls directly to vshard storages by bucket id.
+type Router struct {
+	cfg         Config
+	replicasets map[string]*multi.ConnectionMulti
+	// callStorage calls function on replicaset, it is replaced in tests.
+	callStorage func(uuid string, mode multi.Mode, function string, args, result interface{}) error
+
+	mutex   sync.RWMutex
+	buckets map[uint64]string
+	// discoveries is a number of started discoveries. It is used to share
+	// one discovery among concurrent calls to unknown buckets.
+	discoveries    uint64
+	discoveryMutex sync.Mutex
+	control        chan struct{}
+	once           sync.Once
+}
+
+// Connect connects to all replicasets and discovers buckets.
+func Connect(cfg Config) (*Router, error) {
+	if len(cfg.Replicasets) == 0 {
+		return nil, ErrEmptyReplicasets
+	}
+	r := newRouter(cfg)
+	for uuid, addrs := range r.cfg.Replicasets {
+		pool, err := multi.ConnectWithOpts(addrs, r.cfg.ConnOpts, r.cfg.PoolOpts)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to connect to replicaset %s: %s", uuid, err)
+		}
+		r.replicasets[uuid] = pool
+	}
+	r.Discover()
+	go r.discoverer()
+	return r, nil
+}
+
+// newRouter returns router without connections to replicasets.
+func newRouter(cfg Config) *Router {
+	if cfg.TotalBucketCount == 0 {
+		cfg.TotalBucketCount = DefaultBucketCount
+	}
+	if cfg.PoolOpts.CheckTimeout <= 0 {
+		cfg.PoolOpts.CheckTimeout = time.Second
+	}
+	if cfg.DiscoveryInterval <= 0 {
+		cfg.DiscoveryInterval = defaultDiscoveryInterval
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	r := &Router{
+		cfg:         cfg,
+		replicasets: make(map[string]*multi.ConnectionMulti, len(cfg.Replicasets)),
+		buckets:     make(map[uint64]string),
+		control:     make(chan struct{}),
+	}
+	r.callStorage = r.callPool
+	return r
+}
+
+// Close stops discovery and closes connections to replicasets.
+func (r *Router) Close() (err error) {
+	r.once.Do(func() {
+		close(r.control)
+		for _, pool := range r.replicasets {
+			if closeErr := pool.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	})
+	return
+}
+
+// Replicaset returns pool of replicaset with uuid.
+func (r *Router) Replicaset(uuid string) *multi.ConnectionMulti {
+	return r.replicasets[uuid]
+}
+
+// BucketIDStrCRC32 returns bucket id of key as
+// vshard.router.bucket_id_strcrc32 does.
+func (r *Router) BucketIDStrCRC32(key interface{}) uint64 {
+	return BucketIDStrCRC32(key, r.cfg.TotalBucketCount)
+}
+
+// BucketIDMpCRC32 returns bucket id of key as
+// vshard.router.bucket_id_mpcrc32 does.
+func (r *Router) BucketIDMpCRC32(key interface{}) (uint64, error) {
+	return BucketIDMpCRC32(key, r.cfg.TotalBucketCount)
+}
+
+// CallRO calls function on replicaset, which stores bucket, preferring
+// replicas. It returns results of function.
+func (r *Router) CallRO(bucketID uint64, function string, args interface{}) ([]interface{}, error) {
+	return r.call(bucketID, "read", multi.PreferRO, function, args)
+}
+
+// CallRW calls function on master of replicaset, which stores bucket. It
+// returns results of function.
+func (r *Router) CallRW(bucketID uint64, function string, args interface{}) ([]interface{}, error) {
+	return r.call(bucketID, "write", multi.RW, function, args)
+}
+
+func (r *Router) call(bucketID uint64, access string, mode multi.Mode, function string, args interface{}) ([]interface{}, error) {
+	if bucketID == 0 || bucketID > r.cfg.TotalBucketCount {
+		return nil, ErrWrongBucketID
+	}
+	if args == nil {
+		args = []interface{}{}
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		uuid, routeErr := r.route(bucketID)
+		if routeErr != nil {
+			return nil, routeErr
+		}
+		var res []interface{}
+		err = r.callStorage(uuid, mode, "vshard.storage.call",
+			[]interface{}{bucketID, access, function, args}, &res)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		if results, err = callResults(res); err == nil {
+			return results, nil
+		}
+		storageErr, ok := err.(StorageError)
+		if !ok || attempt >= r.cfg.MaxRetries {
+			return nil, err
+		}
+		switch storageErr.Name {
+		case ErrWrongBucket:
+			// bucket is moved, destination is unknown during rebalancing
+			r.setRoute(bucketID, storageErr.Destination)
+		case ErrBucketIsLocked, ErrTransferIsInProgress:
+			select {
+			case <-time.After(r.cfg.RetryDelay):
+			case <-r.control:
+				return nil, ErrClosed
+			}
+		default:
+			return nil, err
+		}
+	}
+}
+
+func (r *Router) callPool(uuid string, mode multi.Mode, function string, args, result interface{}) error {
+	return r.replicasets[uuid].WithMode(mode).Call17Typed(function, args, result)
+}
+
+// route returns replicaset, which stores bucket. Buckets are rediscovered
+// if it is unknown, unless other discovery is started since then.
+func (r *Router) route(bucketID uint64) (string, error) {
+	r.mutex.RLock()
+	uuid, ok := r.buckets[bucketID]
+	discoveries := r.discoveries
+	r.mutex.RUnlock()
+	if ok {
+		return uuid, nil
+	}
+	r.discoveryMutex.Lock()
+	r.mutex.RLock()
+	started := r.discoveries != discoveries
+	r.mutex.RUnlock()
+	if !started {
+		r.discover()
+	}
+	r.discoveryMutex.Unlock()
+	r.mutex.RLock()
+	uuid, ok = r.buckets[bucketID]
+	r.mutex.RUnlock()
+	if !ok {
+		return "", ErrNoRoute
+	}
+	return uuid, nil
+}
+
+// setRoute sets replicaset of bucket. Route is forgotten if replicaset is
+// unknown.
+func (r *Router) setRoute(bucketID uint64, uuid string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.replicasets[uuid]; ok {
+		r.buckets[bucketID] = uuid
+	} else {
+		delete(r.buckets, bucketID)
+	}
+}
+
+// Discover fetches buckets of all replicasets. Buckets of unavailable
+// replicasets keep their routes.
+func (r *Router) Discover() {
+	r.discoveryMutex.Lock()
+	defer r.discoveryMutex.Unlock()
+	r.discover()
+}
+
+// discover fetches buckets of all replicasets. It should be called under
+// discoveryMutex.
+func (r *Router) discover() {
+	r.mutex.Lock()
+	r.discoveries++
+	r.mutex.Unlock()
+	for uuid := range r.replicasets {
+		buckets, err := r.discoverBuckets(uuid)
+		if err != nil {
+			continue
+		}
+		r.mutex.Lock()
+		for id, owner := range r.buckets {
+			if owner == uuid {
+				delete(r.buckets, id)
+			}
+		}
+		for _, id := range buckets {
+			r.buckets[id] = uuid
+		}
+		r.mutex.Unlock()
+	}
+}
+
+func (r *Router) discoverer() {
+	t := time.NewTicker(r.cfg.DiscoveryInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.control:
+			return
+		case <-t.C:
+			r.Discover()
+		}
+	}
+}
+
+// discoverBuckets returns ids of buckets stored in replicaset.
+func (r *Router) discoverBuckets(uuid string) ([]uint64, error) {
+	var buckets []uint64
+	from := uint64(1)
+	for {
+		var res []interface{}
+		err := r.callStorage(uuid, multi.PreferRO, "vshard.storage.buckets_discovery",
+			[]interface{}{map[string]interface{}{"from": from}}, &res)
+		if err != nil {
+			return nil, err
+		}
+		if len(res) == 0 {
+			return buckets, nil
+		}
+		switch page := res[0].(type) {
+		case []interface{}:
+			// vshard before 0.1.18 returns all buckets at once
+			return appendIDs(buckets, page), nil
+		case map[interface{}]interface{}:
+			ids, _ := page["buckets"].([]interface{})
+			buckets = appendIDs(buckets, ids)
+			next, ok := toUint64(page["next_from"])
+			if !ok {
+				return buckets, nil
+			}
+			from = next
+		default:
+			return nil, fmt.Errorf("unexpected buckets discovery result: %v", res)
+		}
+	}
+}
+
+func appendIDs(buckets []uint64, ids []interface{}) []uint64 {
+	for _, v := range ids {
+		if id, ok := toUint64(v); ok {
+			buckets = append(buckets, id)
+		}
+	}
+	return buckets
+}
+
+// callResults returns results of vshard.storage.call or error returned by
+// storage.
+func callResults(res []interface{}) ([]interface{}, error) {
+	if len(res) == 0 {
+		return nil, nil
+	}
+	switch res[0] {
+	case true:
+		return res[1:], nil
+	case nil:
+		if len(res) > 1 && res[1] != nil {
+			return nil, newStorageError(res[1])
+		}
+	}
+	// old vshard returns results of function only
+	return res, nil
+}
+
+func newStorageError(v interface{}) StorageError {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return StorageError{Message: fmt.Sprint(v)}
+	}
+	e := StorageError{}
+	e.Type, _ = m["type"].(string)
+	e.Name, _ = m["name"].(string)
+	e.Message, _ = m["message"].(string)
+	e.Destination, _ = m["destination"].(string)
+	e.Code, _ = toUint64(m["code"])
+	e.BucketID, _ = toUint64(m["bucket_id"])
+	return e
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint64:
+		return v, true
+	case uint32:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case int64:
+		return uint64(v), v >= 0
+	case int32:
+		return uint64(v), v >= 0
+	case int16:
+		return uint64(v), v >= 0
+	case int8:
+		return uint64(v), v >= 0
+	}
+	return 0, false
+}
@@ -0,0 +1,230 @@
+package vshard
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tarantool/go-tarantool/multi"
+)
+
+func TestBucketIDStrCRC32(t *testing.T) {
+	// digest.crc32('123456789') is 0x1CF96D7C
+	if id := BucketIDStrCRC32("123456789", math.MaxUint64); id != 0x1CF96D7C+1 {
+		t.Errorf("Unexpected checksum: %#x", id-1)
+	}
+	if id := BucketIDStrCRC32("123456789", DefaultBucketCount); id != 541 {
+		t.Errorf("Unexpected bucket id: %d", id)
+	}
+	if BucketIDStrCRC32(123, DefaultBucketCount) != BucketIDStrCRC32("123", DefaultBucketCount) {
+		t.Errorf("Number is not converted to string")
+	}
+	if BucketIDStrCRC32(1.5, DefaultBucketCount) != BucketIDStrCRC32("1.5", DefaultBucketCount) {
+		t.Errorf("Float is not converted to string")
+	}
+}
+
+func TestBucketIDMpCRC32(t *testing.T) {
+	single, err := BucketIDMpCRC32("key", DefaultBucketCount)
+	if err != nil {
+		t.Fatalf("Failed to get bucket id: %s", err.Error())
+	}
+	// "key" is encoded as fixstr
+	if id := BucketIDStrCRC32("\xa3key", DefaultBucketCount); id != single {
+		t.Errorf("Unexpected bucket id: %d, expected %d", single, id)
+	}
+	composite, err := BucketIDMpCRC32([]interface{}{"key", 1}, DefaultBucketCount)
+	if err != nil {
+		t.Fatalf("Failed to get bucket id: %s", err.Error())
+	}
+	if id := BucketIDStrCRC32("\xa3key\x01", DefaultBucketCount); id != composite {
+		t.Errorf("Unexpected bucket id of composite key: %d, expected %d", composite, id)
+	}
+}
+
+func TestCallResults(t *testing.T) {
+	results, err := callResults([]interface{}{true, "a", uint64(1)})
+	if err != nil || !reflect.DeepEqual(results, []interface{}{"a", uint64(1)}) {
+		t.Errorf("Unexpected results: %v, %v", results, err)
+	}
+
+	_, err = callResults([]interface{}{nil, map[interface{}]interface{}{
+		"type":        "ShardingError",
+		"name":        ErrWrongBucket,
+		"code":        uint64(1),
+		"bucket_id":   uint64(10),
+		"destination": "uuid",
+		"message":     "Cannot perform action with bucket 10",
+	}})
+	expected := StorageError{
+		Type:        "ShardingError",
+		Name:        ErrWrongBucket,
+		Code:        1,
+		BucketID:    10,
+		Destination: "uuid",
+		Message:     "Cannot perform action with bucket 10",
+	}
+	if err != expected {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+
+	_, err = callResults([]interface{}{nil, "error"})
+	if err != (StorageError{Message: "error"}) {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+}
+
+// fakeStorages answers calls of router to vshard storages.
+type fakeStorages struct {
+	mutex sync.Mutex
+	// buckets maps replicaset to buckets returned by discovery.
+	buckets map[string][]uint64
+	// results maps replicaset to results of next calls of
+	// vshard.storage.call, replicaset name is returned after them.
+	results     map[string][][]interface{}
+	calls       map[string]int
+	discoveries map[string]int
+}
+
+func newFakeRouter(cfg Config, buckets map[string][]uint64) (*Router, *fakeStorages) {
+	storages := &fakeStorages{
+		buckets:     buckets,
+		results:     make(map[string][][]interface{}),
+		calls:       make(map[string]int),
+		discoveries: make(map[string]int),
+	}
+	r := newRouter(cfg)
+	for uuid := range buckets {
+		r.replicasets[uuid] = nil
+	}
+	r.callStorage = storages.call
+	return r, storages
+}
+
+func (s *fakeStorages) call(uuid string, mode multi.Mode, function string, args, result interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	res := result.(*[]interface{})
+	if function == "vshard.storage.buckets_discovery" {
+		s.discoveries[uuid]++
+		ids := []interface{}{}
+		for _, id := range s.buckets[uuid] {
+			ids = append(ids, id)
+		}
+		*res = []interface{}{map[interface{}]interface{}{"buckets": ids}}
+		return nil
+	}
+	s.calls[uuid]++
+	if results := s.results[uuid]; len(results) > 0 {
+		*res, s.results[uuid] = results[0], results[1:]
+		return nil
+	}
+	*res = []interface{}{true, uuid}
+	return nil
+}
+
+func storageError(name, destination string) []interface{} {
+	return []interface{}{nil, map[interface{}]interface{}{
+		"name":        name,
+		"destination": destination,
+		"message":     name,
+	}}
+}
+
+func TestRouteSharedDiscovery(t *testing.T) {
+	r, storages := newFakeRouter(Config{}, map[string][]uint64{"a": {1}, "b": {2}})
+	r.Discover()
+
+	// calls to unknown bucket wait for running discovery and share next one
+	r.discoveryMutex.Lock()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.route(3); err != ErrNoRoute {
+				t.Errorf("Unexpected error of route to unknown bucket: %v", err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	r.discoveryMutex.Unlock()
+	wg.Wait()
+	if n := storages.discoveries["a"]; n != 2 {
+		t.Errorf("Unexpected number of discoveries: %d", n)
+	}
+
+	storages.mutex.Lock()
+	storages.buckets["b"] = []uint64{2, 3}
+	storages.mutex.Unlock()
+	if uuid, err := r.route(3); err != nil || uuid != "b" {
+		t.Errorf("Unexpected route of moved bucket: %s, %v", uuid, err)
+	}
+}
+
+func TestCallWrongBucket(t *testing.T) {
+	r, storages := newFakeRouter(Config{}, map[string][]uint64{"a": {1}, "b": {}})
+	r.Discover()
+
+	// bucket is moved to known destination
+	storages.results["a"] = [][]interface{}{storageError(ErrWrongBucket, "b")}
+	res, err := r.CallRW(1, "f", nil)
+	if err != nil || !reflect.DeepEqual(res, []interface{}{"b"}) {
+		t.Errorf("Unexpected result of call to moved bucket: %v, %v", res, err)
+	}
+	if uuid, _ := r.route(1); uuid != "b" {
+		t.Errorf("Route is not updated: %s", uuid)
+	}
+
+	// destination is unknown during rebalancing, so bucket is rediscovered
+	storages.results["b"] = [][]interface{}{storageError(ErrWrongBucket, "")}
+	res, err = r.CallRO(1, "f", nil)
+	if err != nil || !reflect.DeepEqual(res, []interface{}{"a"}) {
+		t.Errorf("Unexpected result of call to rediscovered bucket: %v, %v", res, err)
+	}
+	if storages.discoveries["a"] != 2 {
+		t.Errorf("Bucket is not rediscovered")
+	}
+}
+
+func TestCallBucketIsLocked(t *testing.T) {
+	r, storages := newFakeRouter(Config{MaxRetries: 2, RetryDelay: time.Millisecond},
+		map[string][]uint64{"a": {1}})
+	r.Discover()
+
+	storages.results["a"] = [][]interface{}{
+		storageError(ErrBucketIsLocked, ""),
+		storageError(ErrTransferIsInProgress, ""),
+	}
+	res, err := r.CallRW(1, "f", nil)
+	if err != nil || !reflect.DeepEqual(res, []interface{}{"a"}) {
+		t.Errorf("Unexpected result of call to locked bucket: %v, %v", res, err)
+	}
+
+	// retries are limited
+	storages.calls["a"] = 0
+	storages.results["a"] = [][]interface{}{
+		storageError(ErrBucketIsLocked, ""),
+		storageError(ErrBucketIsLocked, ""),
+		storageError(ErrBucketIsLocked, ""),
+	}
+	_, err = r.CallRW(1, "f", nil)
+	if storageErr, ok := err.(StorageError); !ok || storageErr.Name != ErrBucketIsLocked {
+		t.Errorf("Unexpected error after retries: %v", err)
+	}
+	if storages.calls["a"] != 3 {
+		t.Errorf("Unexpected number of calls: %d", storages.calls["a"])
+	}
+
+	// other errors are not retried
+	storages.calls["a"] = 0
+	storages.results["a"] = [][]interface{}{storageError("NO_SUCH_FUNCTION", "")}
+	if _, err = r.CallRW(1, "f", nil); err == nil {
+		t.Errorf("err is nil on storage error")
+	}
+	if storages.calls["a"] != 1 {
+		t.Errorf("Unexpected number of calls: %d", storages.calls["a"])
+	}
+}